@@ -0,0 +1,258 @@
+package ezlog
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+const benchMsg = "The answer to life, the universe, and everything"
+
+func benchLogger(lvl Level) *Logger {
+	return New(lvl, Full, ioutil.Discard, "", LstdFlags)
+}
+
+func BenchmarkError(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Error(benchMsg)
+	}
+}
+
+func BenchmarkErrorf(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Errorf("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkErrorln(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Errorln(benchMsg, 42)
+	}
+}
+
+func BenchmarkErrorDisabled(b *testing.B) {
+	l := benchLogger(LogNone)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Error(benchMsg)
+	}
+}
+
+func BenchmarkErrorfDisabled(b *testing.B) {
+	l := benchLogger(LogNone)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Errorf("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkErrorlnDisabled(b *testing.B) {
+	l := benchLogger(LogNone)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Errorln(benchMsg, 42)
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMsg)
+	}
+}
+
+func BenchmarkInfof(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infof("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkInfoln(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infoln(benchMsg, 42)
+	}
+}
+
+func BenchmarkInfoDisabled(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMsg)
+	}
+}
+
+func BenchmarkInfofDisabled(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infof("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkInfolnDisabled(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Infoln(benchMsg, 42)
+	}
+}
+
+func BenchmarkDebug(b *testing.B) {
+	l := benchLogger(LogDebug)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debug(benchMsg)
+	}
+}
+
+func BenchmarkDebugf(b *testing.B) {
+	l := benchLogger(LogDebug)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkDebugln(b *testing.B) {
+	l := benchLogger(LogDebug)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debugln(benchMsg, 42)
+	}
+}
+
+func BenchmarkDebugDisabled(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debug(benchMsg)
+	}
+}
+
+func BenchmarkDebugfDisabled(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkDebuglnDisabled(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debugln(benchMsg, 42)
+	}
+}
+
+func BenchmarkPrint(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Print(benchMsg)
+	}
+}
+
+func BenchmarkPrintf(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Printf("%s: %d", benchMsg, 42)
+	}
+}
+
+func BenchmarkPrintln(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Println(benchMsg, 42)
+	}
+}
+
+func BenchmarkPanic(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		func() {
+			defer func() { recover() }()
+			l.Panic(benchMsg)
+		}()
+	}
+}
+
+func BenchmarkInfoFields(b *testing.B) {
+	l := benchLogger(LogInfo).With("user", "arthur", "answer", 42, "ok", true)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMsg)
+	}
+}
+
+func BenchmarkInfoParallel(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info(benchMsg)
+		}
+	})
+}
+
+func BenchmarkInfoEntry(b *testing.B) {
+	l := benchLogger(LogInfo)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.InfoEntry().Str("user", "arthur").Int("answer", 42).Msg(benchMsg)
+	}
+}
+
+func BenchmarkInfoEntryDisabled(b *testing.B) {
+	l := benchLogger(LogError)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.InfoEntry().Str("user", "arthur").Int("answer", 42).Msg(benchMsg)
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	tests := []struct {
+		name string
+		lvl  Level
+		f    func(l *Logger)
+	}{
+		{"Error", LogError, func(l *Logger) { l.Error(benchMsg) }},
+		{"Errorf", LogError, func(l *Logger) { l.Errorf("%s: %d", benchMsg, 42) }},
+		{"Errorln", LogError, func(l *Logger) { l.Errorln(benchMsg, 42) }},
+		{"Info", LogInfo, func(l *Logger) { l.Info(benchMsg) }},
+		{"Infof", LogInfo, func(l *Logger) { l.Infof("%s: %d", benchMsg, 42) }},
+		{"Infoln", LogInfo, func(l *Logger) { l.Infoln(benchMsg, 42) }},
+		{"Debug", LogDebug, func(l *Logger) { l.Debug(benchMsg) }},
+		{"Debugf", LogDebug, func(l *Logger) { l.Debugf("%s: %d", benchMsg, 42) }},
+		{"Debugln", LogDebug, func(l *Logger) { l.Debugln(benchMsg, 42) }},
+		{"Print", LogError, func(l *Logger) { l.Print(benchMsg) }},
+		{"Printf", LogError, func(l *Logger) { l.Printf("%s: %d", benchMsg, 42) }},
+		{"Println", LogError, func(l *Logger) { l.Println(benchMsg, 42) }},
+		{"InfoEntry", LogInfo, func(l *Logger) { l.InfoEntry().Str("k", "v").Int("n", 42).Msg(benchMsg) }},
+		{"InfoEntryf", LogInfo, func(l *Logger) { l.InfoEntry().Str("k", "v").Msgf("%s: %d", benchMsg, 42) }},
+	}
+	for _, test := range tests {
+		l := benchLogger(test.lvl).With("user", "arthur", "answer", 42)
+		if n := testing.AllocsPerRun(100, func() { test.f(l) }); n != 0 && !raceEnabled {
+			t.Errorf("%s: got %v allocs; want 0", test.name, n)
+		}
+		l.SetLevel(test.lvl - 1)
+		if n := testing.AllocsPerRun(100, func() { test.f(l) }); n != 0 {
+			t.Errorf("%s disabled: got %v allocs; want 0", test.name, n)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import "sync"
+
+// maxPooledBuffer is the capacity above which buffers aren't returned to the
+// pool, so that an occasional huge message doesn't pin its memory.
+const maxPooledBuffer = 64 << 10
+
+// buffer is a byte slice that messages are formatted into with fmt.Fprint
+// and friends, avoiding the string allocated by fmt.Sprint.
+type buffer []byte
+
+func (b *buffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make(buffer, 0, 256)
+		return &b
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *buffer {
+	return bufferPool.Get().(*buffer)
+}
+
+// putBuffer returns b to the pool.
+func putBuffer(b *buffer) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode controls whether the level string is colored.
+type ColorMode int32
+
+const (
+	ColorAuto   ColorMode = iota // color output to terminals, unless NO_COLOR is set
+	ColorAlways                  // color output to all outputs
+	ColorNever                   // never color output
+)
+
+// ANSI escape sequences that can be used as level colors.
+const (
+	ColorRed     = "\x1b[31m"
+	ColorGreen   = "\x1b[32m"
+	ColorYellow  = "\x1b[33m"
+	ColorBlue    = "\x1b[34m"
+	ColorMagenta = "\x1b[35m"
+	ColorCyan    = "\x1b[36m"
+	ColorBold    = "\x1b[1m"
+	ColorDim     = "\x1b[2m"
+	colorReset   = "\x1b[0m"
+)
+
+var defaultLevelColors = []string{
+	LogError: ColorRed,
+	LogInfo:  ColorCyan,
+	LogDebug: ColorDim,
+	logFatal: ColorBold + ColorRed,
+	logPanic: ColorBold + ColorMagenta,
+}
+
+// GetColorMode returns the logger's color mode.
+func (l *Logger) GetColorMode() ColorMode {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.colorMode
+}
+
+// SetColorMode sets whether the level string in log lines is colored. With
+// ColorAuto, the default, only lines written to an os.File that is a terminal
+// are colored, and only if the NO_COLOR environment variable isn't set; lines
+// written to files, pipes, and other io.Writers never contain escape codes.
+// RecordWriters are given uncolored Records regardless of the mode.
+func (l *Logger) SetColorMode(m ColorMode) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.colorMode = m
+}
+
+// SetLevelColor sets the color of lvl's level string. The color is an ANSI
+// escape sequence, e.g. ColorRed, or a combination of them, e.g.
+// ColorBold+ColorRed; an empty color leaves the level uncolored. Only LogError,
+// LogInfo, LogDebug, LogFatal, and LogPanic can be set; other levels are
+// ignored.
+func (l *Logger) SetLevelColor(lvl Level, color string) {
+	if lvl < LogError || lvl > logPanic {
+		return
+	}
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	if l.levelColors == nil {
+		l.levelColors = append([]string(nil), defaultLevelColors...)
+	}
+	l.levelColors[lvl] = color
+}
+
+// levelColor returns the color of lvl's level string. The caller must hold
+// outMu.
+func (l *Logger) levelColor(lvl Level) string {
+	if l.levelColors == nil {
+		return defaultLevelColors[lvl]
+	}
+	return l.levelColors[lvl]
+}
+
+// colorize reports whether lines written to w are colored. The caller must
+// hold outMu.
+func (l *Logger) colorize(w io.Writer) bool {
+	switch l.colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	if v, ok := l.terminals[f]; ok {
+		return v
+	}
+	v := isTerminal(f) && os.Getenv("NO_COLOR") == ""
+	if l.terminals == nil {
+		l.terminals = make(map[*os.File]bool)
+	}
+	l.terminals[f] = v
+	return v
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// coloredLine returns the line in l.buf with its level string colored. The
+// line is only colored once per log line. The caller must hold outMu.
+func (l *Logger) coloredLine(lvl Level) []byte {
+	if len(l.cbuf) > 0 {
+		return l.cbuf
+	}
+	color := l.levelColor(lvl)
+	if color == "" {
+		return l.buf
+	}
+	l.cbuf = append(l.cbuf, l.buf[:l.levelStart]...)
+	l.cbuf = append(l.cbuf, color...)
+	l.cbuf = append(l.cbuf, l.buf[l.levelStart:l.levelEnd]...)
+	l.cbuf = append(l.cbuf, colorReset...)
+	l.cbuf = append(l.cbuf, l.buf[l.levelEnd:]...)
+	return l.cbuf
+}
+
+// GetColorMode returns the standard logger's color mode.
+func GetColorMode() ColorMode {
+	return std.GetColorMode()
+}
+
+// SetColorMode sets whether the level string in the standard logger's log
+// lines is colored.
+func SetColorMode(m ColorMode) {
+	std.SetColorMode(m)
+}
+
+// SetLevelColor sets the color of lvl's level string for the standard logger.
+func SetLevelColor(lvl Level, color string) {
+	std.SetLevelColor(lvl, color)
+}
@@ -0,0 +1,110 @@
+package ezlog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestColorMode(t *testing.T) {
+	tests := []struct {
+		mode     ColorMode
+		expected string
+	}{
+		{ColorAuto, "ERROR: a\nINFO: b\nDEBUG: c\n"},
+		{ColorNever, "ERROR: a\nINFO: b\nDEBUG: c\n"},
+		{ColorAlways, "\x1b[31mERROR:\x1b[0m a\n\x1b[36mINFO:\x1b[0m b\n\x1b[2mDEBUG:\x1b[0m c\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		l := New(LogDebug, Full, &buf, "", 0)
+		l.SetColorMode(test.mode)
+		if l.GetColorMode() != test.mode {
+			t.Errorf("%d: got mode %d; want %d", test.mode, l.GetColorMode(), test.mode)
+		}
+		l.Error("a")
+		l.Info("b")
+		l.Debug("c")
+		if buf.String() != test.expected {
+			t.Errorf("%d: got %q; want %q", test.mode, buf.String(), test.expected)
+		}
+	}
+}
+
+func TestSetLevelColor(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Char, &buf, "pfx ", 0).With("k", "v")
+	l.SetColorMode(ColorAlways)
+	l.SetLevelColor(LogError, ColorBold+ColorYellow)
+	l.SetLevelColor(LogDebug, "")
+	l.SetLevelColor(LogFatal, ColorGreen)
+	l.SetLevelColor(logPanic+1, ColorGreen) // ignored
+	l.Error("a")
+	l.Debug("b")
+	l.Print("c")
+	expected := "pfx \x1b[1m\x1b[33mE:\x1b[0m a k=v\npfx D: b k=v\npfx c k=v\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	if l.levelColor(LogFatal) != ColorGreen {
+		t.Errorf("fatal: got %q; want %q", l.levelColor(LogFatal), ColorGreen)
+	}
+	if l.levelColor(LogPanic) != defaultLevelColors[LogPanic] {
+		t.Errorf("panic: got %q; want %q", l.levelColor(LogPanic), defaultLevelColors[LogPanic])
+	}
+	if len(l.levelColors) != len(defaultLevelColors) {
+		t.Errorf("got %d level colors; want %d", len(l.levelColors), len(defaultLevelColors))
+	}
+}
+
+func TestColorizeOutputs(t *testing.T) {
+	f, err := ioutil.TempFile("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0)
+	l.AddOutput(f, LogDebug)
+	l.SetColorMode(ColorAlways)
+	l.Error("a")
+	l.SetColorMode(ColorAuto)
+	l.Error("b")
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "\x1b[31mERROR:\x1b[0m a\nERROR: b\n" {
+		t.Errorf("file: got %q", b)
+	}
+	if l.colorize(f) {
+		t.Error("a regular file is colored in ColorAuto")
+	}
+}
+
+func TestColorizeTerminal(t *testing.T) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	if !isTerminal(f) {
+		t.Skipf("%s isn't a character device", os.DevNull)
+	}
+	t.Setenv("NO_COLOR", "")
+	l := New(LogDebug, Full, f, "", 0)
+	if !l.colorize(f) {
+		t.Error("NO_COLOR unset: expected the terminal to be colored")
+	}
+	t.Setenv("NO_COLOR", "1")
+	l = New(LogDebug, Full, f, "", 0)
+	if l.colorize(f) {
+		t.Error("NO_COLOR set: expected the terminal not to be colored")
+	}
+	l.SetColorMode(ColorAlways)
+	if !l.colorize(f) {
+		t.Error("ColorAlways: expected the terminal to be colored")
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config is a declarative Logger configuration. The zero value is a valid
+// Config that results in a Logger configured like the standard logger: its
+// level is LogError, it uses the Full level names, and it writes to stderr
+// with LstdFlags.
+//
+// A Config can be loaded from JSON:
+//
+//	{
+//	    "level": "info",
+//	    "level_string_type": "short",
+//	    "prefix": "[svc] ",
+//	    "flags": ["date", "time", "shortfile"],
+//	    "format": "text",
+//	    "outputs": [
+//	        {"type": "stderr", "level": "error"},
+//	        {"type": "file", "path": "/var/log/svc.log",
+//	         "rotation": {"max_size": 10485760, "max_backups": 5}}
+//	    ]
+//	}
+type Config struct {
+	// Level is the Logger's level. Its value is parsed with LevelByName;
+	// empty string defaults to LogError.
+	Level string `json:"level"`
+	// LevelStringType is the type of string to use for level names. Its
+	// value is parsed with LevelStringTypeByName; empty string defaults to
+	// Full.
+	LevelStringType string `json:"level_string_type"`
+	// Prefix is what each line will start with.
+	Prefix string `json:"prefix"`
+	// Flags are the names of the flags to use; each name is parsed with
+	// ParseFlag and the results are or'ed together. A nil Flags defaults to
+	// LstdFlags.
+	Flags []string `json:"flags"`
+	// Format is the format of the log lines. Its value is parsed with
+	// FormatByName; empty string defaults to "text".
+	Format string `json:"format"`
+	// Outputs are the Logger's output destinations. If there are no
+	// Outputs, the Logger writes to stderr.
+	Outputs []OutputConfig `json:"outputs"`
+}
+
+// OutputConfig is the configuration for a single Logger output destination.
+type OutputConfig struct {
+	// Type is the type of output: "stderr", "stdout", or "file".
+	Type string `json:"type"`
+	// Path is the name of the file to write to; it is required for, and only
+	// valid with, file outputs.
+	Path string `json:"path"`
+	// Level further restricts the lines that are written to this output.
+	// Its value is parsed with LevelByName; empty string means that all
+	// lines that pass the Logger's level are written.
+	Level string `json:"level"`
+	// Rotation enables the rotation of file outputs.
+	Rotation *RotationConfig `json:"rotation"`
+}
+
+// RotationConfig is the rotation configuration for a file output; see
+// RotatingFile.
+type RotationConfig struct {
+	MaxSize    int64 `json:"max_size"`    // The max size of the file, in bytes.
+	MaxBackups int   `json:"max_backups"` // The number of rotated files to keep.
+}
+
+// ConfigError occurs when a Config field has an invalid value.
+type ConfigError struct {
+	Field string // The offending field, e.g. "outputs[1].level".
+	Err   error  // Why the field's value is invalid.
+}
+
+func (e ConfigError) Error() string {
+	return "invalid config " + e.Field + ": " + e.Err.Error()
+}
+
+// LoadConfig reads a JSON encoded Config from r and validates it. Unknown
+// fields are treated as an error.
+func LoadConfig(r io.Reader) (*Config, error) {
+	var c Config
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&c)
+	if err != nil {
+		return nil, err
+	}
+	err = c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LoadConfigFile reads and validates the JSON encoded Config in the named file.
+// Errors are prefixed with the file's name; a validation error wraps the
+// ConfigError, which can be retrieved with errors.As.
+func LoadConfigFile(name string) (*Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := LoadConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return c, nil
+}
+
+// Validate checks that all of c's fields have valid values. The returned
+// error, if any, is a ConfigError.
+func (c *Config) Validate() error {
+	_, err := c.parse()
+	return err
+}
+
+// NewFromConfig creates a new Logger from c. Any files that are opened for
+// the Logger's outputs are closed when the Logger's Close method is called.
+func NewFromConfig(c *Config) (*Logger, error) {
+	p, err := c.parse()
+	if err != nil {
+		return nil, err
+	}
+	outputs, closers, err := c.openOutputs(p.levels)
+	if err != nil {
+		return nil, err
+	}
+	l := New(p.level, p.stringType, nil, c.Prefix, p.flag)
+	l.format = p.format
+	if len(outputs) == 0 {
+		l.out = os.Stderr
+	}
+	l.outputs = outputs
+	l.closers = closers
+	return l, nil
+}
+
+// configSettings are the parsed values of a Config.
+type configSettings struct {
+	level      Level
+	stringType LevelStringType
+	flag       int
+	format     Format
+	levels     []Level // the levels of the outputs
+}
+
+func (c *Config) parse() (configSettings, error) {
+	s := configSettings{level: LogError, flag: LstdFlags}
+	var ok bool
+	if c.Level != "" {
+		s.level, ok = LevelByName(c.Level)
+		if !ok {
+			return s, ConfigError{"level", UnknownLevelError{c.Level}}
+		}
+	}
+	s.stringType, ok = LevelStringTypeByName(c.LevelStringType)
+	if !ok {
+		return s, ConfigError{"level_string_type", fmt.Errorf("unknown level string type: %s", c.LevelStringType)}
+	}
+	if c.Flags != nil {
+		s.flag = 0
+		for i, v := range c.Flags {
+			f, err := ParseFlag(v)
+			if err != nil {
+				return s, ConfigError{"flags[" + strconv.Itoa(i) + "]", err}
+			}
+			s.flag |= f
+		}
+	}
+	s.format, ok = FormatByName(c.Format)
+	if !ok {
+		return s, ConfigError{"format", fmt.Errorf("unknown format: %s", c.Format)}
+	}
+	for i, o := range c.Outputs {
+		field := "outputs[" + strconv.Itoa(i) + "]"
+		lvl := LogDebug
+		if o.Level != "" {
+			lvl, ok = LevelByName(o.Level)
+			if !ok {
+				return s, ConfigError{field + ".level", UnknownLevelError{o.Level}}
+			}
+		}
+		s.levels = append(s.levels, lvl)
+		switch strings.ToLower(o.Type) {
+		case "stderr", "stdout":
+			if o.Path != "" {
+				return s, ConfigError{field + ".path", errors.New("only file outputs have a path")}
+			}
+			if o.Rotation != nil {
+				return s, ConfigError{field + ".rotation", errors.New("only file outputs can be rotated")}
+			}
+		case "file":
+			if o.Path == "" {
+				return s, ConfigError{field + ".path", errors.New("file outputs require a path")}
+			}
+			if o.Rotation == nil {
+				continue
+			}
+			if o.Rotation.MaxSize < 0 {
+				return s, ConfigError{field + ".rotation.max_size", errors.New("must not be negative")}
+			}
+			if o.Rotation.MaxBackups < 0 {
+				return s, ConfigError{field + ".rotation.max_backups", errors.New("must not be negative")}
+			}
+		default:
+			return s, ConfigError{field + ".type", fmt.Errorf("unknown output type: %s", o.Type)}
+		}
+	}
+	return s, nil
+}
+
+// openOutputs opens c's outputs. The closers are for any files that were
+// opened. If an error occurs, any files that were already opened are closed.
+func (c *Config) openOutputs(levels []Level) (outputs []output, closers []io.Closer, err error) {
+	for i, o := range c.Outputs {
+		var w io.Writer
+		switch strings.ToLower(o.Type) {
+		case "stderr":
+			w = os.Stderr
+		case "stdout":
+			w = os.Stdout
+		case "file":
+			var f io.WriteCloser
+			if o.Rotation != nil {
+				f, err = OpenRotatingFile(o.Path, o.Rotation.MaxSize, o.Rotation.MaxBackups)
+			} else {
+				f, err = os.OpenFile(o.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+			}
+			if err != nil {
+				for _, cl := range closers {
+					cl.Close()
+				}
+				return nil, nil, ConfigError{"outputs[" + strconv.Itoa(i) + "].path", err}
+			}
+			w = f
+			closers = append(closers, f)
+		}
+		outputs = append(outputs, output{w: w, level: levels[i]})
+	}
+	return outputs, closers, nil
+}
@@ -0,0 +1,196 @@
+package ezlog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	s := `{
+		"level": "debug",
+		"level_string_type": "short",
+		"prefix": "[svc] ",
+		"flags": ["date", "shortfile"],
+		"format": "text",
+		"outputs": [
+			{"type": "stderr", "level": "error"},
+			{"type": "file", "path": "x.log", "rotation": {"max_size": 1024, "max_backups": 3}}
+		]
+	}`
+	c, err := LoadConfig(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	p, err := c.parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p.level != LogDebug {
+		t.Errorf("level: got %s; want %s", p.level, LogDebug)
+	}
+	if p.stringType != Short {
+		t.Errorf("level string type: got %d; want %d", p.stringType, Short)
+	}
+	if p.flag != Ldate|Lshortfile {
+		t.Errorf("flags: got %d; want %d", p.flag, Ldate|Lshortfile)
+	}
+	if len(p.levels) != 2 || p.levels[0] != LogError || p.levels[1] != LogDebug {
+		t.Errorf("output levels: got %v; want [%s %s]", p.levels, LogError, LogDebug)
+	}
+	if c.Outputs[1].Rotation == nil || c.Outputs[1].Rotation.MaxSize != 1024 || c.Outputs[1].Rotation.MaxBackups != 3 {
+		t.Errorf("rotation: got %+v; want {MaxSize:1024 MaxBackups:3}", c.Outputs[1].Rotation)
+	}
+
+	_, err = LoadConfig(strings.NewReader(`{"levle": "debug"}`))
+	if err == nil {
+		t.Error("unknown field: expected an error; got none")
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	var c Config
+	p, err := c.parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p.level != LogError {
+		t.Errorf("level: got %s; want %s", p.level, LogError)
+	}
+	if p.stringType != Full {
+		t.Errorf("level string type: got %d; want %d", p.stringType, Full)
+	}
+	if p.flag != LstdFlags {
+		t.Errorf("flags: got %d; want %d", p.flag, LstdFlags)
+	}
+	l, err := NewFromConfig(&c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if l.out != os.Stderr {
+		t.Errorf("output: got %v; want os.Stderr", l.out)
+	}
+	c.Flags = []string{"none"}
+	p, err = c.parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p.flag != 0 {
+		t.Errorf("flags: got %d; want 0", p.flag)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		c     Config
+		field string
+		err   string
+	}{
+		{Config{Level: "warn"}, "level", "invalid config level: unknown log level: warn"},
+		{Config{LevelStringType: "long"}, "level_string_type", "invalid config level_string_type: unknown level string type: long"},
+		{Config{Flags: []string{"date", "zdate"}}, "flags[1]", "invalid config flags[1]: unknown log flag: zdate"},
+		{Config{Format: "xml"}, "format", "invalid config format: unknown format: xml"},
+		{Config{Outputs: []OutputConfig{{Type: "stderr"}, {Type: "pipe"}}}, "outputs[1].type", "invalid config outputs[1].type: unknown output type: pipe"},
+		{Config{Outputs: []OutputConfig{{Type: "stdout", Level: "verbose"}}}, "outputs[0].level", "invalid config outputs[0].level: unknown log level: verbose"},
+		{Config{Outputs: []OutputConfig{{Type: "file"}}}, "outputs[0].path", "invalid config outputs[0].path: file outputs require a path"},
+		{Config{Outputs: []OutputConfig{{Type: "stdout", Path: "x.log"}}}, "outputs[0].path", "invalid config outputs[0].path: only file outputs have a path"},
+		{Config{Outputs: []OutputConfig{{Type: "stdout", Rotation: &RotationConfig{}}}}, "outputs[0].rotation", "invalid config outputs[0].rotation: only file outputs can be rotated"},
+		{Config{Outputs: []OutputConfig{{Type: "file", Path: "x.log", Rotation: &RotationConfig{MaxSize: -1}}}}, "outputs[0].rotation.max_size", "invalid config outputs[0].rotation.max_size: must not be negative"},
+		{Config{Outputs: []OutputConfig{{Type: "file", Path: "x.log", Rotation: &RotationConfig{MaxBackups: -1}}}}, "outputs[0].rotation.max_backups", "invalid config outputs[0].rotation.max_backups: must not be negative"},
+	}
+	for _, test := range tests {
+		err := test.c.Validate()
+		if err == nil {
+			t.Errorf("%s: expected an error; got none", test.field)
+			continue
+		}
+		cerr, ok := err.(ConfigError)
+		if !ok {
+			t.Errorf("%s: expected a ConfigError; got %T", test.field, err)
+			continue
+		}
+		if cerr.Field != test.field {
+			t.Errorf("field: got %q; want %q", cerr.Field, test.field)
+		}
+		if err.Error() != test.err {
+			t.Errorf("%s: got %q; want %q", test.field, err, test.err)
+		}
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	errName := filepath.Join(dir, "error.log")
+	allName := filepath.Join(dir, "all.log")
+	c := Config{
+		Level:   "info",
+		Prefix:  "x ",
+		Flags:   []string{"none"},
+		Outputs: []OutputConfig{{Type: "file", Path: errName, Level: "error"}, {Type: "file", Path: allName, Rotation: &RotationConfig{MaxSize: 1 << 20}}},
+	}
+	l, err := NewFromConfig(&c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	l.Error("error")
+	l.Info("info")
+	l.Debug("debug")
+	l.Close()
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{errName, "x ERROR: error\n"},
+		{allName, "x ERROR: error\nx INFO: info\n"},
+	}
+	for _, test := range tests {
+		b, err := ioutil.ReadFile(test.name)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%s: got %q; want %q", test.name, b, test.expected)
+		}
+	}
+
+	c.Outputs = []OutputConfig{{Type: "file", Path: filepath.Join(dir, "nodir", "x.log")}}
+	_, err = NewFromConfig(&c)
+	if err == nil {
+		t.Fatal("expected an error; got none")
+	}
+	if cerr, ok := err.(ConfigError); !ok || cerr.Field != "outputs[0].path" {
+		t.Errorf("got %v; want a ConfigError for outputs[0].path", err)
+	}
+}
+
+func TestLoadConfigFileError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "config.json")
+	err = ioutil.WriteFile(name, []byte(`{"level": "loud"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadConfigFile(name)
+	var cerr ConfigError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("got %v; want a ConfigError", err)
+	}
+	if cerr.Field != "level" {
+		t.Errorf("got field %q; want %q", cerr.Field, "level")
+	}
+	if !strings.HasPrefix(err.Error(), name+": ") {
+		t.Errorf("got %q; want it prefixed with the file name", err)
+	}
+}
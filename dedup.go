@@ -0,0 +1,111 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+)
+
+// dedup is the state of the collapsing of duplicate lines.
+type dedup struct {
+	window time.Duration
+	lvl    Level
+	msg    []byte // of the last line written
+	// what the summary line needs; n is the number of repeats not yet
+	// reported.
+	n      int
+	file   string
+	line   int
+	fields []Field
+	timer  *time.Timer
+}
+
+// SetDedup collapses consecutive duplicate lines, lines with the same level
+// and message, into the first line and a "last message repeated N times" line,
+// like syslogd. The summary line is written when a different line is logged,
+// when window has passed since the first repeat, or when the logger is closed.
+// A window of 0 or less stops the collapsing of lines; the summary of any
+// repeats is written first.
+func (l *Logger) SetDedup(window time.Duration) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.flushDedup()
+	if window <= 0 {
+		l.dedup = nil
+		return
+	}
+	l.dedup = &dedup{window: window}
+}
+
+// dedupLine reports whether the line is a repeat of the last line, in which
+// case it's counted instead of written. Otherwise, the summary of the last
+// line's repeats, if any, is written and the line becomes the last line. The
+// caller must hold outMu.
+func (l *Logger) dedupLine(lvl Level, file string, line int, msg []byte) bool {
+	d := l.dedup
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+	if d.msg != nil && lvl == d.lvl && bytes.Equal(msg, d.msg) {
+		if d.n == 0 {
+			core := l.logger
+			d.timer = time.AfterFunc(d.window, func() {
+				core.outMu.Lock()
+				defer core.outMu.Unlock()
+				if core.dedup == d {
+					(&Logger{logger: core}).flushDedup()
+				}
+			})
+		}
+		d.n++
+		// the fields are copied: an Entry's fields are reused once it's written.
+		d.file, d.line = file, line
+		d.fields = append(d.fields[:0], l.fields[:len(l.fields)-l.errFields]...)
+		return true
+	}
+	l.flushDedup()
+	d.lvl = lvl
+	d.msg = append(d.msg[:0], msg...)
+	return false
+}
+
+// flushDedup writes the summary of the last line's repeats, if there are any.
+// The caller must hold outMu.
+func (l *Logger) flushDedup() {
+	d := l.dedup
+	if d == nil || d.n == 0 {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	msg := "last message repeated " + strconv.Itoa(d.n) + " times"
+	sl := &Logger{logger: l.logger, fields: d.fields}
+	d.n = 0
+	// restored rather than cleared: the line being written when the summary
+	// is flushed may be one of the logger's own, e.g. a rate limit summary.
+	internal := l.internal
+	l.internal = true
+	sl.formatLine(l.now(), d.lvl, d.file, d.line, []byte(msg))
+	l.internal = internal
+}
+
+// SetDedup collapses the standard logger's consecutive duplicate lines into
+// the first line and a "last message repeated N times" line.
+func SetDedup(window time.Duration) {
+	std.SetDedup(window)
+}
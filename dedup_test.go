@@ -0,0 +1,60 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0).With("k", "v")
+	l.SetDedup(time.Hour)
+	l.Error("a")
+	l.Errorln("a")
+	l.Errorf("%s", "a")
+	l.Info("a") // a different level isn't a repeat
+	l.Info("b")
+	l.Info("b")
+	l.Info("c")
+	l.Info("c")
+	l.Close()
+	expected := "" +
+		"ERROR: a k=v\n" +
+		"ERROR: last message repeated 2 times k=v\n" +
+		"INFO: a k=v\n" +
+		"INFO: b k=v\n" +
+		"INFO: last message repeated 1 times k=v\n" +
+		"INFO: c k=v\n" +
+		"INFO: last message repeated 1 times k=v\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	buf.Reset()
+	l.Info("c")
+	l.SetDedup(0)
+	l.Info("c")
+	if buf.String() != "INFO: last message repeated 1 times k=v\nINFO: c k=v\n" {
+		t.Errorf("got %q; want %q", buf.String(), "INFO: last message repeated 1 times k=v\nINFO: c k=v\n")
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	var w syncBuffer
+	l := New(LogDebug, Full, &w, "", 0)
+	l.SetDedup(10 * time.Millisecond)
+	l.Info("a")
+	l.Info("a")
+	l.Info("a")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(w.String(), "repeated") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	l.Info("a")
+	l.Close()
+	expected := "INFO: a\nINFO: last message repeated 2 times\nINFO: last message repeated 1 times\n"
+	if w.String() != expected {
+		t.Errorf("got %q; want %q", w.String(), expected)
+	}
+}
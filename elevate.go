@@ -0,0 +1,99 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// elevation is a temporary raise of a Logger's level.
+type elevation struct {
+	level Level
+}
+
+// SetLevelFor raises the logger's level to lvl for the duration d. The
+// returned cancel func ends the elevation early; calling it after the
+// elevation has ended is a no-op.
+//
+// Elevations can overlap. While any are active, the logger's level is the
+// highest of the elevated levels and the level it would otherwise have. Once
+// the last one ends, the level that the logger had before the first one began
+// is restored, unless SetLevel was called in the meantime, in which case that
+// level is used. Elevating to a level lower than the logger's current level
+// does not lower it.
+func (l *Logger) SetLevelFor(lvl Level, d time.Duration) (cancel func()) {
+	e := &elevation{level: lvl}
+	l.levelMu.Lock()
+	if len(l.elevations) == 0 {
+		l.baseLevel = Level(atomic.LoadInt32(&l.level))
+	}
+	l.elevations = append(l.elevations, e)
+	l.applyElevations()
+	l.levelMu.Unlock()
+	t := time.AfterFunc(d, func() { l.endElevation(e) })
+	return func() {
+		t.Stop()
+		l.endElevation(e)
+	}
+}
+
+// endElevation removes e from the logger's active elevations, if it is
+// still active, and updates the logger's level accordingly.
+func (l *Logger) endElevation(e *elevation) {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+	for i, v := range l.elevations {
+		if v != e {
+			continue
+		}
+		l.elevations = append(l.elevations[:i], l.elevations[i+1:]...)
+		if len(l.elevations) == 0 {
+			atomic.StoreInt32(&l.level, int32(l.baseLevel))
+			return
+		}
+		l.applyElevations()
+		return
+	}
+}
+
+// applyElevations sets the logger's level to the highest of its base level
+// and its active elevations. The caller must hold levelMu.
+func (l *Logger) applyElevations() {
+	lvl := l.baseLevel
+	for _, e := range l.elevations {
+		if e.level > lvl {
+			lvl = e.level
+		}
+	}
+	atomic.StoreInt32(&l.level, int32(lvl))
+}
+
+// configuredLevel returns the level set with SetLevel, or by a Config; while
+// the level is elevated, this is the level that will be restored, not the
+// logger's current level.
+func (l *Logger) configuredLevel() Level {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+	if len(l.elevations) == 0 {
+		return Level(atomic.LoadInt32(&l.level))
+	}
+	return l.baseLevel
+}
+
+// SetLevelFor raises the standard logger's level to lvl for the duration d.
+// The returned cancel func ends the elevation early. See Logger.SetLevelFor.
+func SetLevelFor(lvl Level, d time.Duration) (cancel func()) {
+	return std.SetLevelFor(lvl, d)
+}
@@ -0,0 +1,69 @@
+package ezlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSetLevelFor(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogError, Full, &buf, "", 0)
+	cancel := l.SetLevelFor(LogDebug, time.Hour)
+	if l.GetLevel() != LogDebug {
+		t.Errorf("elevated: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	l.Debug("debug")
+	if buf.String() != "DEBUG: debug\n" {
+		t.Errorf("got %q; want \"DEBUG: debug\\n\"", buf.String())
+	}
+	cancel()
+	if l.GetLevel() != LogError {
+		t.Errorf("cancelled: got %s; want %s", l.GetLevel(), LogError)
+	}
+	cancel() // must be a no-op
+	if l.GetLevel() != LogError {
+		t.Errorf("cancelled twice: got %s; want %s", l.GetLevel(), LogError)
+	}
+
+	l.SetLevelFor(LogInfo, 10*time.Millisecond)
+	if l.GetLevel() != LogInfo {
+		t.Errorf("elevated: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+	if !waitForLevel(l, LogError) {
+		t.Errorf("expired: got %s; want %s", l.GetLevel(), LogError)
+	}
+}
+
+func TestSetLevelForOverlapping(t *testing.T) {
+	l := New(LogError, Full, &bytes.Buffer{}, "", 0)
+	cancelDebug := l.SetLevelFor(LogDebug, time.Hour)
+	cancelInfo := l.SetLevelFor(LogInfo, time.Hour)
+	if l.GetLevel() != LogDebug {
+		t.Errorf("overlapping: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	cancelDebug()
+	if l.GetLevel() != LogInfo {
+		t.Errorf("debug cancelled: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+	// a level set during an elevation is restored when it ends
+	l.SetLevel(LogNone)
+	if l.GetLevel() != LogInfo {
+		t.Errorf("set during elevation: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+	cancelInfo()
+	if l.GetLevel() != LogNone {
+		t.Errorf("info cancelled: got %s; want %s", l.GetLevel(), LogNone)
+	}
+
+	// elevating to a lower level doesn't lower the level
+	l.SetLevel(LogDebug)
+	cancel := l.SetLevelFor(LogError, time.Hour)
+	if l.GetLevel() != LogDebug {
+		t.Errorf("lower elevation: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	cancel()
+	if l.GetLevel() != LogDebug {
+		t.Errorf("lower elevation cancelled: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// entryCallDepth is the calldepth, for output, of the lines written by an
+// Entry's Msg and Msgf. These are always called by the user, even for the
+// entries of the standard logger, so it doesn't depend on the Logger.
+const entryCallDepth = 3
+
+// An Entry is a log line that is built by adding fields to it, ending with a
+// call to Msg or Msgf, which writes the line:
+//
+//	l.InfoEntry().Str("user", u).Int("n", n).Dur("took", d).Msg("done")
+//
+// The fields are written after the fields bound to the Logger. If the line's
+// level is disabled, or the line isn't kept by the level's Sampler, the Entry
+// is nil: its methods do nothing, so the fields' values aren't converted and
+// the message isn't formatted. An Entry must not be used after Msg or Msgf is
+// called: Entries are reused.
+type Entry struct {
+	l      *Logger
+	lvl    Level
+	fields []Field
+	err    error
+}
+
+// maxPooledFields is the capacity above which an Entry's fields aren't
+// returned to the pool, like maxPooledBuffer.
+const maxPooledFields = 64
+
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return &Entry{fields: make([]Field, 0, 16)}
+	},
+}
+
+// putEntry returns e to the pool.
+func putEntry(e *Entry) {
+	if cap(e.fields) > maxPooledFields {
+		return
+	}
+	// clear the fields so that the pool doesn't keep their values alive
+	for i := range e.fields {
+		e.fields[i] = Field{}
+	}
+	e.l, e.fields, e.err = nil, e.fields[:0], nil
+	entryPool.Put(e)
+}
+
+// ErrorEntry returns an Entry for an error line; nil if the logger's level is
+// less than LogError.
+func (l *Logger) ErrorEntry() *Entry {
+	return l.entry(LogError)
+}
+
+// InfoEntry returns an Entry for an info line; nil if the logger's level is
+// less than LogInfo or the line isn't kept by the LogInfo Sampler.
+func (l *Logger) InfoEntry() *Entry {
+	return l.entry(LogInfo)
+}
+
+// DebugEntry returns an Entry for a debug line; nil if the logger's level is
+// less than LogDebug or the line isn't kept by the LogDebug Sampler.
+func (l *Logger) DebugEntry() *Entry {
+	return l.entry(LogDebug)
+}
+
+// entry returns an Entry for a line of level lvl; nil if the line will be
+// discarded.
+func (l *Logger) entry(lvl Level) *Entry {
+	if atomic.LoadInt32(&l.level) < int32(lvl) || l.sampledOut(lvl) {
+		return nil
+	}
+	e := entryPool.Get().(*Entry)
+	e.l, e.lvl = l, lvl
+	e.fields = append(e.fields, l.fields...)
+	return e
+}
+
+// Str adds a string field to the Entry.
+func (e *Entry) Str(key, val string) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Int adds an int field to the Entry.
+func (e *Entry) Int(key string, val int) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Int64 adds an int64 field to the Entry.
+func (e *Entry) Int64(key string, val int64) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Float64 adds a float64 field to the Entry.
+func (e *Entry) Float64(key string, val float64) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Bool adds a bool field to the Entry.
+func (e *Entry) Bool(key string, val bool) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Dur adds a time.Duration field to the Entry.
+func (e *Entry) Dur(key string, val time.Duration) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Time adds a time.Time field to the Entry.
+func (e *Entry) Time(key string, val time.Time) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Any adds a field of any type to the Entry.
+func (e *Entry) Any(key string, val interface{}) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.fields = append(e.fields, Field{Key: key, Value: val})
+	return e
+}
+
+// Err adds err to the Entry; it's written in the same way as the error of a
+// line written by Logger.Err, after the other fields.
+func (e *Entry) Err(err error) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.err = err
+	return e
+}
+
+// Msg writes the Entry's line with the message msg.
+func (e *Entry) Msg(msg string) {
+	if e == nil {
+		return
+	}
+	if rl := e.l.rateLimiter(); rl == nil || !rl.limited(e.l, e.lvl, entryCallDepth-1, msg) {
+		// a pooled buffer, rather than []byte(msg), which would allocate
+		b := getBuffer()
+		*b = append(*b, msg...)
+		e.write(*b)
+		putBuffer(b)
+	}
+	putEntry(e)
+}
+
+// Msgf writes the Entry's line with the message formatted in the manner of
+// fmt.Printf.
+func (e *Entry) Msgf(format string, v ...interface{}) {
+	if e == nil {
+		return
+	}
+	if rl := e.l.rateLimiter(); rl == nil || !rl.limited(e.l, e.lvl, entryCallDepth-1, format) {
+		b := getBuffer()
+		fmt.Fprintf(b, format, v...)
+		e.write(*b)
+		putBuffer(b)
+	}
+	putEntry(e)
+}
+
+// write writes the Entry's line with the message msg.
+func (e *Entry) write(msg []byte) {
+	n := len(e.fields)
+	if e.err != nil {
+		e.fields = appendErrorFields(e.fields, e.err)
+	}
+	e.l.outputFields(e.lvl, entryCallDepth, e.fields, len(e.fields)-n, msg)
+}
+
+// ErrorEntry returns an Entry for an error line of the standard logger.
+func ErrorEntry() *Entry {
+	return std.ErrorEntry()
+}
+
+// InfoEntry returns an Entry for an info line of the standard logger.
+func InfoEntry() *Entry {
+	return std.InfoEntry()
+}
+
+// DebugEntry returns an Entry for a debug line of the standard logger.
+func DebugEntry() *Entry {
+	return std.DebugEntry()
+}
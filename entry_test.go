@@ -0,0 +1,87 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEntry(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0).With("app", "x")
+	l.InfoEntry().Str("user", "arthur").Int("n", 42).Int64("big", 1<<40).Float64("f", 1.5).Bool("ok", true).Dur("took", 1500*time.Millisecond).Any("v", []int{1}).Msg("done")
+	l.DebugEntry().Str("user", "ford").Msgf("%d towels", 2)
+	l.ErrorEntry().Str("path", "/etc/app.json").Err(errors.New("boom")).Msg("load failed")
+	expected := "" +
+		"INFO: done app=x user=arthur n=42 big=1099511627776 f=1.5 ok=true took=1.5s v=[1]\n" +
+		"DEBUG: 2 towels app=x user=ford\n" +
+		"ERROR: load failed app=x path=/etc/app.json\n" +
+		"\terror: boom (*errors.errorString)\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	if len(l.Fields()) != 1 {
+		t.Errorf("got %v; want the logger's fields unchanged", l.Fields())
+	}
+}
+
+func TestEntryDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogError, Full, &buf, "", 0)
+	if e := l.InfoEntry(); e != nil {
+		t.Errorf("got %v; want a nil Entry", e)
+	}
+	var formatted bool
+	l.DebugEntry().Str("k", "v").Int("n", 1).Err(errors.New("boom")).Msgf("%v", stringerFunc(func() string { formatted = true; return "" }))
+	if formatted || buf.Len() != 0 {
+		t.Errorf("got %q; want the line discarded without being formatted", buf.String())
+	}
+	n := testing.AllocsPerRun(100, func() {
+		l.InfoEntry().Str("user", "arthur").Int("n", 42).Dur("took", time.Second).Msg("done")
+	})
+	if n != 0 {
+		t.Errorf("got %v allocs; want 0", n)
+	}
+	l.SetLevel(LogDebug)
+	l.SetSampler(LogInfo, NewRatioSampler(0))
+	if e := l.InfoEntry(); e != nil {
+		t.Errorf("got %v; want a nil Entry for a line that isn't kept", e)
+	}
+}
+
+func TestEntryCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", Lshortfile)
+	_, file, line, _ := runtime.Caller(0)
+	l.InfoEntry().Str("k", "v").Msg("a")
+	expected := shortFile(file) + ":" + strconv.Itoa(line+1) + ": INFO: a k=v\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	var rw recordBuffer
+	SetOutput(&rw)
+	defer SetOutput(os.Stderr)
+	SetLevel(LogInfo)
+	defer SetLevel(LogError)
+	InfoEntry().Msgf("%s", "b")
+	if len(rw.records) != 1 || rw.records[0].File != file || rw.records[0].Line != line+11 {
+		t.Errorf("got %v; want a record from %s:%d", rw.records, file, line+11)
+	}
+}
+
+func TestEntryDedup(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0)
+	l.SetDedup(time.Hour)
+	l.InfoEntry().Str("k", "a").Msg("x")
+	l.InfoEntry().Str("k", "a").Msg("x")
+	l.InfoEntry().Str("k", "b").Msg("y")
+	expected := "INFO: x k=a\nINFO: last message repeated 1 times k=a\nINFO: y k=b\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
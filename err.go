@@ -0,0 +1,156 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// The keys of the fields that describe the error of a line written by Err.
+const (
+	ErrorKey      = "error"       // the error's message
+	ErrorChainKey = "error_chain" // an ErrorChain
+	ErrorStackKey = "error_stack" // the stack attached to the error, if any
+)
+
+// ErrorLink is an error in an ErrorChain.
+type ErrorLink struct {
+	Type    string // The error's concrete type, e.g. *fs.PathError.
+	Message string // The error's message.
+}
+
+// ErrorChain is an error followed by the errors it wraps, as returned by
+// errors.Unwrap.
+type ErrorChain []ErrorLink
+
+// String returns the links of the chain as "type: message" separated by "; ".
+func (c ErrorChain) String() string {
+	var b strings.Builder
+	for i, e := range c {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.Type)
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	return b.String()
+}
+
+// Err writes an error line to the logger with the message msg, the keyvals,
+// and fields describing err: its message, the chain of errors it wraps, with
+// their concrete types, and the stack attached to it, if any. Stacks are found
+// on errors that have a StackTrace method, e.g. those from
+// github.com/pkg/errors, or a Stack method returning a []byte or string; the
+// stack of the innermost such error is used.
+//
+// RecordWriters get err as the ErrorKey, ErrorChainKey, and ErrorStackKey
+// fields. In text lines, err is written as a trailer of indented lines after
+// the line. If the logger's level is less than LogError, the line will be
+// discarded. A nil err writes the line without the error's fields.
+func (l *Logger) Err(err error, msg string, keyvals ...interface{}) {
+	if atomic.LoadInt32(&l.level) < int32(LogError) {
+		return
+	}
+	if rl := l.rateLimiter(); rl != nil && rl.limited(l, LogError, l.callDepth, msg) {
+		return
+	}
+	fields := appendKeyvals(append([]Field(nil), l.fields...), keyvals)
+	n := len(fields)
+	if err != nil {
+		fields = appendErrorFields(fields, err)
+	}
+	el := &Logger{logger: l.logger, fields: fields, callDepth: l.callDepth, errFields: len(fields) - n}
+	el.output(LogError, l.callDepth, []byte(msg))
+}
+
+// appendErrorFields appends the fields describing err to fields.
+func appendErrorFields(fields []Field, err error) []Field {
+	var chain ErrorChain
+	var stack string
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		chain = append(chain, ErrorLink{Type: fmt.Sprintf("%T", e), Message: e.Error()})
+		if s := errorStack(e); s != "" {
+			stack = s
+		}
+	}
+	fields = append(fields, Field{Key: ErrorKey, Value: err.Error()}, Field{Key: ErrorChainKey, Value: chain})
+	if stack != "" {
+		fields = append(fields, Field{Key: ErrorStackKey, Value: stack})
+	}
+	return fields
+}
+
+// errorStack returns the stack attached to err, not to the errors it wraps;
+// an empty string if it doesn't have one.
+func errorStack(err error) string {
+	switch e := err.(type) {
+	case interface{ Stack() []byte }:
+		return strings.TrimSpace(string(e.Stack()))
+	case interface{ Stack() string }:
+		return strings.TrimSpace(e.Stack())
+	}
+	// the StackTrace method's result is a type of the package that defines
+	// the error, so it can't be asserted.
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%+v", m.Call(nil)[0].Interface()))
+}
+
+// appendErrorTrailer appends the lines describing the error in fields, the
+// fields added by Err, to buf; each line is preceded by indent.
+func appendErrorTrailer(buf []byte, fields []Field, indent []byte) []byte {
+	for _, f := range fields {
+		switch v := f.Value.(type) {
+		case ErrorChain:
+			for i, e := range v {
+				buf = append(buf, indent...)
+				if i == 0 {
+					buf = append(buf, "error: "...)
+				} else {
+					buf = append(buf, "caused by: "...)
+				}
+				buf = append(buf, e.Message...)
+				buf = append(buf, " ("...)
+				buf = append(buf, e.Type...)
+				buf = append(buf, ")\n"...)
+			}
+		case string:
+			if f.Key != ErrorStackKey {
+				continue
+			}
+			buf = append(buf, indent...)
+			buf = append(buf, "stack:\n"...)
+			for _, s := range strings.Split(v, "\n") {
+				buf = append(buf, indent...)
+				buf = append(buf, indent...)
+				buf = append(buf, s...)
+				buf = append(buf, '\n')
+			}
+		}
+	}
+	return buf
+}
+
+// Err writes an error line to the standard logger with the message msg, the
+// keyvals, and fields describing err. See Logger.Err.
+func Err(err error, msg string, keyvals ...interface{}) {
+	std.Err(err, msg, keyvals...)
+}
@@ -0,0 +1,131 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// stackError is an error with a stack attached, in the manner of
+// github.com/pkg/errors.
+type stackError struct {
+	msg string
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) StackTrace() fakeStack { return fakeStack{"main.f", "/app/main.go:12"} }
+
+type fakeStack []string
+
+func (s fakeStack) Format(f fmt.State, verb rune) {
+	for i := 0; i < len(s); i += 2 {
+		fmt.Fprintf(f, "\n%s\n\t%s", s[i], s[i+1])
+	}
+}
+
+func testError() error {
+	return fmt.Errorf("load config: %w", fmt.Errorf("read: %w", io.ErrUnexpectedEOF))
+}
+
+func TestErr(t *testing.T) {
+	var buf bytes.Buffer
+	var rw recordBuffer
+	l := New(LogError, Full, &buf, "", 0).With("app", "x")
+	l.AddOutput(&rw, LogError)
+	l.Err(testError(), "starting failed", "attempt", 3)
+	expected := "" +
+		"ERROR: starting failed app=x attempt=3\n" +
+		"\terror: load config: read: unexpected EOF (*fmt.wrapError)\n" +
+		"\tcaused by: read: unexpected EOF (*fmt.wrapError)\n" +
+		"\tcaused by: unexpected EOF (*errors.errorString)\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	if len(rw.records) != 1 {
+		t.Fatalf("got %d records; want 1", len(rw.records))
+	}
+	r := rw.records[0]
+	if r.Message != "starting failed" || len(r.Fields) != 4 {
+		t.Fatalf("got %q %v; want the message and 4 fields", r.Message, r.Fields)
+	}
+	if r.Fields[2].Key != ErrorKey || r.Fields[2].Value != "load config: read: unexpected EOF" {
+		t.Errorf("got %v; want the error's message", r.Fields[2])
+	}
+	chain, ok := r.Fields[3].Value.(ErrorChain)
+	if r.Fields[3].Key != ErrorChainKey || !ok || len(chain) != 3 || chain[2].Type != "*errors.errorString" {
+		t.Errorf("got %v; want the error chain", r.Fields[3])
+	}
+	s := "*fmt.wrapError: load config: read: unexpected EOF; *fmt.wrapError: read: unexpected EOF; *errors.errorString: unexpected EOF"
+	if chain.String() != s {
+		t.Errorf("got %q; want %q", chain.String(), s)
+	}
+	if len(l.Fields()) != 1 {
+		t.Errorf("got %v; want the logger's fields unchanged", l.Fields())
+	}
+}
+
+func TestErrStack(t *testing.T) {
+	var buf bytes.Buffer
+	var rw recordBuffer
+	l := New(LogError, Full, &buf, "", 0)
+	l.AddOutput(&rw, LogError)
+	l.Err(fmt.Errorf("wrapped: %w", &stackError{"boom"}), "failed")
+	expected := "" +
+		"ERROR: failed\n" +
+		"\terror: wrapped: boom (*fmt.wrapError)\n" +
+		"\tcaused by: boom (*ezlog.stackError)\n" +
+		"\tstack:\n" +
+		"\t\tmain.f\n" +
+		"\t\t\t/app/main.go:12\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	r := rw.records[0]
+	if len(r.Fields) != 3 || r.Fields[2].Key != ErrorStackKey || r.Fields[2].Value != "main.f\n\t/app/main.go:12" {
+		t.Errorf("got %v; want the stack", r.Fields)
+	}
+}
+
+func TestErrConsole(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogError, Full, &buf, "", 0)
+	l.SetFormat(ConsoleFormat)
+	l.Err(errors.New("boom"), "failed", "k", "v")
+	expected := "ERROR failed" + strings.Repeat(" ", 34) + " k=v\n      error: boom (*errors.errorString)\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+func TestErrLevelAndNil(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogNone, Full, &buf, "", 0)
+	l.Err(errors.New("boom"), "failed")
+	if buf.Len() != 0 {
+		t.Errorf("got %q; want the line discarded", buf.String())
+	}
+	l.SetLevel(LogError)
+	l.Err(nil, "no error", "k", "v")
+	if buf.String() != "ERROR: no error k=v\n" {
+		t.Errorf("got %q; want %q", buf.String(), "ERROR: no error k=v\n")
+	}
+}
+
+func TestErrRedact(t *testing.T) {
+	var buf bytes.Buffer
+	var rw recordBuffer
+	l := New(LogError, Full, &buf, "", 0)
+	l.AddOutput(&rw, LogError)
+	l.AddRedactor(RedactDefaults())
+	l.Err(fmt.Errorf("auth: %w", errors.New("bad header Bearer abc.def")), "request failed")
+	if strings.Contains(buf.String(), "abc.def") {
+		t.Errorf("got %q; want the token redacted", buf.String())
+	}
+	if s := fmt.Sprint(rw.records[0].Fields); strings.Contains(s, "abc.def") {
+		t.Errorf("got %s; want the token redacted", s)
+	}
+}
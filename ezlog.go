@@ -17,22 +17,23 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package ezlog provides simple leveled log output using stdlib's log.Logger.
-// A type Logger is defined with methods for leveled log line and formatted
-// leveled log line output. For convenience, a 'standard' logger is available
-// through the helper functions. The 'standard' logger's log level is LogError
-// and writes output to stderr with LstdFlags.
+// Package ezlog provides simple leveled log output with an api similar to
+// stdlib's log.Logger. A type Logger is defined with methods for leveled log
+// line and formatted leveled log line output. For convenience, a 'standard'
+// logger is available through the helper functions. The 'standard' logger's
+// log level is LogError and writes output to stderr with LstdFlags.
 //
 // In addition to LogNone, which discards all log lines, three common log
 // levels are supported: error (LogError), info (LogInfo), and debug
 // (LogDebug). Any log lines that are for log levels higher than the logger's
-// log level are discarded.
+// log level are discarded before they are formatted.
 //
 // Leveled log lines are written with the Error[f|ln], Info[f|ln], Debug[f|ln]
 // methods. Aside from the leveled log lines, two other types of prefixed log
 // lines can be written: Fatal[f|ln] and Panic[f|ln]. Log lines w/o levels can
 // be written with the Print[f|ln] methods. These methods will always result in
-// the log lines being written.
+// the log lines being written. Errors can be written with Err, which records
+// the chain of errors that the error wraps.
 //
 // On Fatal and Panic calls, Logger can run functions prior to os.Exit or
 // panic. These functions are added using the AddFunc call and are expected to
@@ -40,36 +41,60 @@
 // execution of these functions will be ignored.
 //
 // These functions can also be run by calling the Close method.
+//
+// A Logger writes to its output, set with SetOutput, and to any additional
+// outputs added with AddOutput. Each additional output has its own level, which
+// further restricts the lines that are written to it. Outputs that implement
+// RecordWriter, e.g. SyslogWriter, are given each line's Record instead of the
+// formatted line. A Logger can also be created from a Config, which can be
+// loaded from a JSON file.
+//
+// When writing to a terminal, the level string is colored by level; see
+// SetColorMode and SetLevelColor.
+//
+// Secrets, e.g. passwords and bearer tokens, can be masked before lines are
+// written to any output by adding a Redactor; see AddRedactor and
+// RedactDefaults.
 package ezlog
 
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // These flags define which text to prefix to each log entry generated by the Logger.
 const (
 	// Bits or'ed together to control what's printed.
-	// There is no control over the order they appear (the order listed
-	// here) or the format they present (as described in the comments).
-	// The prefix is followed by a colon only when Llongfile or Lshortfile
-	// is specified.
+	// There is no control over the order the date, time, and file appear
+	// (the order listed here) or the format they present (as described in
+	// the comments). The prefix is at the beginning of the line and the
+	// level string is just before the message, unless Lmsgprefix or
+	// Llevelfirst are specified. When the prefix and the level string are
+	// next to each other, the prefix comes first, unless
+	// Llevelbeforeprefix is specified.
 	// For example, flags Ldate | Ltime (or LstdFlags) produce,
-	//	2009/01/23 01:23:23 message
+	//	2009/01/23 01:23:23 ERROR: message
 	// while flags Ldate | Ltime | Lmicroseconds | Llongfile produce,
-	//	2009/01/23 01:23:23.123123 /a/b/c/d.go:23: message
-	Ldate         = 1 << iota     // the date in the local time zone: 2009/01/23
-	Ltime                         // the time in the local time zone: 01:23:23
-	Lmicroseconds                 // microsecond resolution: 01:23:23.123123.  assumes Ltime.
-	Llongfile                     // full file name and line number: /a/b/c/d.go:23
-	Lshortfile                    // final file name element and line number: d.go:23. overrides Llongfile
-	LUTC                          // if Ldate or Ltime is set, use UTC rather than the local time zone
-	LstdFlags     = Ldate | Ltime // initial values for the standard logger
+	//	2009/01/23 01:23:23.123123 /a/b/c/d.go:23: ERROR: message
+	// and, with the prefix "[svc] ", flags LstdFlags | Lmsgprefix produce,
+	//	2009/01/23 01:23:23 [svc] ERROR: message
+	Ldate              = 1 << iota     // the date in the local time zone: 2009/01/23
+	Ltime                              // the time in the local time zone: 01:23:23
+	Lmicroseconds                      // microsecond resolution: 01:23:23.123123.  assumes Ltime.
+	Llongfile                          // full file name and line number: /a/b/c/d.go:23
+	Lshortfile                         // final file name element and line number: d.go:23. overrides Llongfile
+	LUTC                               // if Ldate or Ltime is set, use UTC rather than the local time zone
+	Lmsgprefix                         // move the prefix from the beginning of the line to before the message
+	Llevelfirst                        // move the level string from before the message to the beginning of the line
+	Llevelbeforeprefix                 // put the level string before the prefix when they're next to each other
+	LstdFlags          = Ldate | Ltime // initial values for the standard logger
 )
 
 // Level: log levels.
@@ -98,6 +123,21 @@ const (
 	Char                         // use the first character of the level's name
 )
 
+var levelStringTypeName = []string{
+	Full:  "full",
+	Short: "short",
+	Char:  "char",
+}
+
+// String returns the name of the LevelStringType; the name can be parsed
+// with LevelStringTypeByName.
+func (t LevelStringType) String() string {
+	if t < 0 || int(t) >= len(levelStringTypeName) {
+		return "LevelStringType(" + strconv.Itoa(int(t)) + ")"
+	}
+	return levelStringTypeName[t]
+}
+
 var levelChar = []string{
 	LogNone:  "NONE:", // this is the fullword because it should never be used
 	LogError: "E:",
@@ -138,10 +178,11 @@ var level = []string{
 // doesn't match any Levels. S is uppper-cased prior to evaluation.
 //
 // Supported values:
-//    LogNone:   none, n, empty string ("")
-//    LogError:  error, e, error
-//    LogInfo:   info, i, inf
-//    LogDebug:  debug, d, dbg
+//
+//	LogNone:   none, n, empty string ("")
+//	LogError:  error, e, error
+//	LogInfo:   info, i, inf
+//	LogDebug:  debug, d, dbg
 func LevelByName(s string) (level Level, ok bool) {
 	v := strings.ToUpper(s)
 	switch v {
@@ -158,6 +199,38 @@ func LevelByName(s string) (level Level, ok bool) {
 	}
 }
 
+// LevelStringTypeByName gets the LevelStringType corresponding to s. A false
+// will be returned if s doesn't match any LevelStringTypes. S is lower-cased
+// prior to evaluation.
+//
+// Supported values:
+//
+//	Full:   full, empty string ("")
+//	Short:  short
+//	Char:   char
+func LevelStringTypeByName(s string) (typ LevelStringType, ok bool) {
+	v := strings.ToLower(s)
+	switch v {
+	case "full", "":
+		return Full, true
+	case "short":
+		return Short, true
+	case "char":
+		return Char, true
+	default:
+		return 0, false
+	}
+}
+
+// UnknownLevelError occurs when a string cannot be parsed into a Level.
+type UnknownLevelError struct {
+	S string // The string that could not be parsed to a valid Level.
+}
+
+func (e UnknownLevelError) Error() string {
+	return "unknown log level: " + e.S
+}
+
 // UnknownFlagError occurs when a string cannot be parsed into a log Flag.
 type UnknownFlagError struct {
 	S string // The string that could not be parsed to a valid Flag.
@@ -190,6 +263,12 @@ func ParseFlag(s string) (l int, err error) {
 		return Lshortfile, nil
 	case "lutc", "utc":
 		return LUTC, nil
+	case "lmsgprefix", "msgprefix":
+		return Lmsgprefix, nil
+	case "llevelfirst", "levelfirst":
+		return Llevelfirst, nil
+	case "llevelbeforeprefix", "levelbeforeprefix":
+		return Llevelbeforeprefix, nil
 	case "lstdflags", "stdflags":
 		return LstdFlags, nil
 	case "none":
@@ -198,15 +277,97 @@ func ParseFlag(s string) (l int, err error) {
 	return 0, UnknownFlagError{s}
 }
 
+// flagNames are the names of the individual flags, in flag order.
+var flagNames = []string{"date", "time", "microseconds", "longfile", "shortfile", "utc", "msgprefix", "levelfirst", "levelbeforeprefix"}
+
+// FlagNames returns the names of the flags that are set in flag. Each name can
+// be parsed with ParseFlag. If no flags are set, the result is "none".
+func FlagNames(flag int) []string {
+	if flag == 0 {
+		return []string{"none"}
+	}
+	var names []string
+	for i, name := range flagNames {
+		if flag&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// A Record is a single log line, as given to a RecordWriter.
+type Record struct {
+	Time    time.Time // When the line was logged.
+	Level   Level     // The line's level; 0 for lines without a level.
+	Message string    // The message, without a trailing newline.
+	Fields  []Field   // The fields bound to the Logger; must not be modified.
+	File    string    // The caller's file.
+	Line    int       // The caller's line.
+}
+
+// RecordWriter is implemented by outputs that need a line's Record instead of
+// the formatted line, e.g. outputs that have their own framing or that map the
+// Level to a severity of their own. When a Logger's output implements
+// RecordWriter, WriteRecord is called instead of Write. The Record must not be
+// retained after WriteRecord returns.
+type RecordWriter interface {
+	WriteRecord(r *Record) error
+}
+
 // Logger generates leveled log lines of output to an io.Writer if the log
 // level is <= the logger's level. This is safe for concurrent use.
+//
+// A Logger can have fields bound to it, see With. Loggers created by With
+// share everything but their fields with the Logger they were created from.
 type Logger struct {
-	l          *log.Logger
-	level      int32          // sync.AtomicInt32
-	stringType int32          // sync.AtomicInt32
-	funcs      []func() error // funcs to be run by Close
-	mu         sync.Mutex     // this protects the funcs only
-	callDepth  int
+	*logger
+	fields    []Field // bound fields
+	callDepth int
+	errFields int // the number of trailing fields that describe an error; see Err
+}
+
+// logger is the state that is shared by a Logger and the Loggers that are
+// created from it by With.
+type logger struct {
+	outMu       sync.Mutex              // ensures atomic writes; protects the following fields
+	prefix      string                  // prefix to write at beginning of each line
+	flag        int                     // properties
+	out         io.Writer               // destination for output; may be nil
+	outputs     []output                // additional destinations, each with its own level
+	levelOuts   [logPanic + 1]io.Writer // per level destinations; nil falls back to out
+	closers     []io.Closer             // outputs opened by the logger; closed by Close
+	buf         []byte                  // for accumulating text to write
+	cbuf        []byte                  // buf with its level string colored
+	levelStart  int                     // where the level string starts in buf
+	levelEnd    int                     // where the level string ends in buf
+	format      Format
+	timeFormat  string                         // supersedes the date and time flags
+	location    *time.Location                 // nil for the flags' time zone
+	clock       func() time.Time               // nil for time.Now
+	caller      func() (file string, line int) // nil for runtime.Caller
+	limiter     atomic.Value                   // *rateLimiter; nil if lines aren't rate limited
+	samplers    atomic.Value                   // *samplers; nil if every line is kept
+	dedup       *dedup                         // nil if duplicate lines aren't collapsed
+	redactors   []Redactor
+	hooks       []Hook
+	internal    bool // a line of the logger's own is being written; hooks aren't fired for it
+	colorMode   ColorMode
+	levelColors []string          // nil for the default colors
+	terminals   map[*os.File]bool // whether outputs are colored in ColorAuto
+	level       int32             // sync.AtomicInt32
+	stringType  int32             // sync.AtomicInt32
+	funcs       []func() error    // funcs to be run by Close
+	mu          sync.Mutex        // this protects the funcs only
+	levelMu     sync.Mutex        // protects baseLevel and elevations
+	baseLevel   Level             // level to restore when all elevations end
+	elevations  []*elevation      // active SetLevelFor elevations
+}
+
+// output is an additional log destination. Only lines that its level allows
+// are written to it.
+type output struct {
+	w     io.Writer
+	level Level
 }
 
 // New creates a new Logger. The level argument sets the Logger's log level.
@@ -215,7 +376,7 @@ type Logger struct {
 // argument sets the log data output destination. The prefix argument sets what
 // each line will start with. The flag argument sets the logger's properties.
 func New(level Level, levelStringType LevelStringType, out io.Writer, prefix string, flag int) *Logger {
-	return &Logger{l: log.New(out, prefix, flag), level: int32(level), stringType: int32(levelStringType), callDepth: 2}
+	return &Logger{logger: &logger{out: out, prefix: prefix, flag: flag, level: int32(level), stringType: int32(levelStringType)}, callDepth: 2}
 }
 
 // AddFunc adds a func to the logger that is to be run by the Close, Fatal, and
@@ -226,15 +387,29 @@ func (l *Logger) AddFunc(f func() error) {
 	l.mu.Unlock()
 }
 
-// Close runs any funcs that the logger was given. Any errors that occurs
-// during the execution of these funcs are ignored as this is expected to occur
-// immediately before the application exits.
+// Close runs any funcs that the logger was given and then closes any outputs
+// that the logger opened, e.g. the files of a Logger created from a Config.
+// Any errors that occurs during the execution of these funcs are ignored as
+// this is expected to occur immediately before the application exits.
 func (l *Logger) Close() {
+	if rl := l.rateLimiter(); rl != nil {
+		rl.flushAll(l.logger)
+	}
+	l.outMu.Lock()
+	l.flushDedup()
+	l.outMu.Unlock()
 	l.mu.Lock()
 	for _, f := range l.funcs {
 		f()
 	}
 	l.mu.Unlock()
+	l.outMu.Lock()
+	closers := l.closers
+	l.closers = nil
+	l.outMu.Unlock()
+	for _, c := range closers {
+		c.Close()
+	}
 }
 
 // Error writes an error line to the logger. If the logger's level is less than
@@ -244,7 +419,7 @@ func (l *Logger) Error(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogError) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprint(append([]interface{}{l.levelString(LogError), " "}, v...)...))
+	l.print(LogError, v)
 }
 
 // Errorf writes an error line to the logger using the provided format and
@@ -254,7 +429,7 @@ func (l *Logger) Errorf(format string, v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogError) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintf(fmt.Sprintf("%s %s", l.levelString(LogError), format), v...))
+	l.printf(LogError, format, v)
 }
 
 // Errorln writes an error line to the logger. If the logger's level is less
@@ -264,7 +439,7 @@ func (l *Logger) Errorln(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogError) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintln(append([]interface{}{l.levelString(LogError)}, v...)...))
+	l.println(LogError, v)
 }
 
 // Info writes an info entry to the logger. If the level is less than LogInfo,
@@ -274,7 +449,7 @@ func (l *Logger) Info(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogInfo) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprint(append([]interface{}{l.levelString(LogInfo), " "}, v...)...))
+	l.print(LogInfo, v)
 }
 
 // Infof writes an info line to the logger using the provided format and data.
@@ -284,7 +459,7 @@ func (l *Logger) Infof(format string, v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogInfo) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintf(fmt.Sprintf("%s %s", l.levelString(LogInfo), format), v...))
+	l.printf(LogInfo, format, v)
 }
 
 // Infoln writes an info entry to the logger. If the level is less than
@@ -294,7 +469,7 @@ func (l *Logger) Infoln(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogInfo) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintln(append([]interface{}{l.levelString(LogInfo)}, v...)...))
+	l.println(LogInfo, v)
 }
 
 // Debug writes a debug line to the logger. If the level is less than LogDebug,
@@ -304,7 +479,7 @@ func (l *Logger) Debug(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogDebug) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprint(append([]interface{}{l.levelString(LogDebug), " "}, v...)...))
+	l.print(LogDebug, v)
 }
 
 // Debugf writes a debug line to the logger using the provided format and data.
@@ -314,7 +489,7 @@ func (l *Logger) Debugf(format string, v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogDebug) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintf(fmt.Sprintf("%s %s", l.levelString(LogDebug), format), v...))
+	l.printf(LogDebug, format, v)
 }
 
 // Debugln writes a debug line to the logger. If the level is less than
@@ -324,13 +499,13 @@ func (l *Logger) Debugln(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) < int32(LogDebug) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintln(append([]interface{}{l.levelString(LogDebug)}, v...)...))
+	l.println(LogDebug, v)
 }
 
 // Fatal writes a fatal line to the logger followed by a call to os.Exit(1).
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Fatal(v ...interface{}) {
-	l.l.Output(l.callDepth, fmt.Sprint(append([]interface{}{l.levelString(logFatal), " "}, v...)...))
+	l.print(logFatal, v)
 	l.Close()
 	os.Exit(1)
 }
@@ -338,7 +513,7 @@ func (l *Logger) Fatal(v ...interface{}) {
 // Fatalf writes a fatal line to the logger using the provided format and data
 // followed by a call to os.Exit(1).
 func (l *Logger) Fatalf(format string, v ...interface{}) {
-	l.l.Output(l.callDepth, fmt.Sprintf(fmt.Sprintf("%s %s", l.levelString(logFatal), format), v...))
+	l.printf(logFatal, format, v)
 	l.Close()
 	os.Exit(1)
 }
@@ -346,7 +521,7 @@ func (l *Logger) Fatalf(format string, v ...interface{}) {
 // Fatalln writes a fatal line to the logger followed by a call to os.Exit(1).
 // Arguments are handled in the manner of fmt.Println.
 func (l *Logger) Fatalln(v ...interface{}) {
-	l.l.Output(l.callDepth, fmt.Sprintln(append([]interface{}{l.levelString(logFatal)}, v...)...))
+	l.println(logFatal, v)
 	l.Close()
 	os.Exit(1)
 }
@@ -354,28 +529,28 @@ func (l *Logger) Fatalln(v ...interface{}) {
 // Panic writes a panic line to the logger followed by a call to panic().
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Panic(v ...interface{}) {
-	s := fmt.Sprint(append([]interface{}{l.levelString(LogDebug), " "}, v...)...)
-	l.l.Output(l.callDepth, s)
+	s := fmt.Sprint(v...)
+	l.output(logPanic, l.callDepth, []byte(s))
 	l.Close()
-	panic(s)
+	panic(l.levelString(logPanic) + " " + l.redactMessage(s))
 }
 
 // Panicf writes a panic line to the logger using the provided format and data
 // followed by a call to panic().
 func (l *Logger) Panicf(format string, v ...interface{}) {
-	s := fmt.Sprintf(fmt.Sprintf("%s %s", l.levelString(logPanic), format), v...)
-	l.l.Output(l.callDepth, s)
+	s := fmt.Sprintf(format, v...)
+	l.output(logPanic, l.callDepth, []byte(s))
 	l.Close()
-	panic(s)
+	panic(l.levelString(logPanic) + " " + l.redactMessage(s))
 }
 
 // Panicln writes a panic line to the logger followed by a call to panic().
 // Arguments are handled in the manner of fmt.Println.
 func (l *Logger) Panicln(v ...interface{}) {
-	s := fmt.Sprintln(append([]interface{}{l.levelString(LogDebug)}, v...)...)
-	l.l.Output(l.callDepth, s)
+	s := fmt.Sprintln(v...)
+	l.output(logPanic, l.callDepth, []byte(s))
 	l.Close()
-	panic(s)
+	panic(l.levelString(logPanic) + " " + l.redactMessage(s))
 }
 
 // Print writes a log line to the logger. Unless the logger's level is LogNone,
@@ -385,7 +560,7 @@ func (l *Logger) Print(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) <= int32(LogNone) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprint(v...))
+	l.print(0, v)
 }
 
 // Printf writes a log line to the logger. Unless the logger's level is
@@ -395,7 +570,7 @@ func (l *Logger) Printf(format string, v ...interface{}) {
 	if atomic.LoadInt32(&l.level) <= int32(LogNone) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintf(format, v...))
+	l.printf(0, format, v)
 }
 
 // Println writes a log line to the logger. Unless the logger's level is
@@ -405,17 +580,72 @@ func (l *Logger) Println(v ...interface{}) {
 	if atomic.LoadInt32(&l.level) <= int32(LogNone) {
 		return
 	}
-	l.l.Output(l.callDepth, fmt.Sprintln(v...))
+	l.println(0, v)
+}
+
+// print writes a log line of level lvl with v formatted in the manner of
+// fmt.Print.
+func (l *Logger) print(lvl Level, v []interface{}) {
+	if l.sampledOut(lvl) {
+		return
+	}
+	rl := l.rateLimiter()
+	if rl != nil && rl.key == RateLimitCaller && rl.limited(l, lvl, l.callDepth+1, "") {
+		return
+	}
+	b := getBuffer()
+	fmt.Fprint(b, v...)
+	if rl == nil || rl.key == RateLimitCaller || !rl.limited(l, lvl, l.callDepth+1, string(*b)) {
+		l.output(lvl, l.callDepth+1, *b)
+	}
+	putBuffer(b)
+}
+
+// printf writes a log line of level lvl with v formatted in the manner of
+// fmt.Printf.
+func (l *Logger) printf(lvl Level, format string, v []interface{}) {
+	if l.sampledOut(lvl) {
+		return
+	}
+	if rl := l.rateLimiter(); rl != nil && rl.limited(l, lvl, l.callDepth+1, format) {
+		return
+	}
+	b := getBuffer()
+	fmt.Fprintf(b, format, v...)
+	l.output(lvl, l.callDepth+1, *b)
+	putBuffer(b)
+}
+
+// println writes a log line of level lvl with v formatted in the manner of
+// fmt.Println.
+func (l *Logger) println(lvl Level, v []interface{}) {
+	if l.sampledOut(lvl) {
+		return
+	}
+	rl := l.rateLimiter()
+	if rl != nil && rl.key == RateLimitCaller && rl.limited(l, lvl, l.callDepth+1, "") {
+		return
+	}
+	b := getBuffer()
+	fmt.Fprintln(b, v...)
+	if rl == nil || rl.key == RateLimitCaller || !rl.limited(l, lvl, l.callDepth+1, string(*b)) {
+		l.output(lvl, l.callDepth+1, *b)
+	}
+	putBuffer(b)
 }
 
 // Flags returns the logger's output flags.
 func (l *Logger) Flags() int {
-	return l.l.Flags()
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.flag
 }
 
 // SetFlags sets the logger's flags.
 func (l *Logger) SetFlags(flags int) {
-	l.l.SetFlags(flags)
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.flag = flags
 }
 
 // GetLevel returns the logger's level.
@@ -424,24 +654,74 @@ func (l *Logger) GetLevel() Level {
 }
 
 // SetLevel sets the maximum level for the logger's output. Any log lines
-// whose levels are higher than the logger's level will be discarded.
+// whose levels are higher than the logger's level will be discarded. If the
+// level is temporarily elevated, see SetLevelFor, i is the level that will be
+// restored once the elevation ends; until then, the level is the higher of i
+// and the elevated level.
 func (l *Logger) SetLevel(i Level) {
-	atomic.StoreInt32((*int32)(&l.level), int32(i))
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+	if len(l.elevations) == 0 {
+		atomic.StoreInt32((*int32)(&l.level), int32(i))
+		return
+	}
+	l.baseLevel = i
+	l.applyElevations()
 }
 
 // SetOutput sets the logger's output.
 func (l *Logger) SetOutput(w io.Writer) {
-	l.l.SetOutput(w)
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.out = w
+}
+
+// SetLevelOutput sets the output for the lines of level lvl, e.g. os.Stderr
+// for LogError, LogFatal, and LogPanic lines and os.Stdout for LogInfo and
+// LogDebug lines. The lines of levels without their own output, including
+// lines without a level, 0, are written to the output set with SetOutput. A
+// nil w restores the use of that output for lvl. The additional outputs added
+// with AddOutput aren't affected.
+func (l *Logger) SetLevelOutput(lvl Level, w io.Writer) {
+	if lvl < 0 || int(lvl) >= len(l.levelOuts) {
+		return
+	}
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.levelOuts[lvl] = w
+}
+
+// outputFor returns the output for the lines of level lvl. The caller must
+// hold outMu.
+func (l *Logger) outputFor(lvl Level) io.Writer {
+	if lvl >= 0 && int(lvl) < len(l.levelOuts) && l.levelOuts[lvl] != nil {
+		return l.levelOuts[lvl]
+	}
+	return l.out
+}
+
+// AddOutput adds an additional output destination to the logger. Lines are
+// written to w only if they pass both the logger's level and the output's
+// level. Lines without a level, and Fatal and Panic lines, are written to w
+// unless its level is LogNone.
+func (l *Logger) AddOutput(w io.Writer, level Level) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.outputs = append(l.outputs, output{w: w, level: level})
 }
 
 // Prefix returns the logger's prefix.
 func (l *Logger) Prefix() string {
-	return l.l.Prefix()
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.prefix
 }
 
 // SetPrefix sets the logger's prefix.
 func (l *Logger) SetPrefix(prefix string) {
-	l.l.SetPrefix(prefix)
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.prefix = prefix
 }
 
 // GetLevelStringType returns what the logger is using for the error level
@@ -456,24 +736,327 @@ func (l *Logger) SetLevelStringType(v LevelStringType) {
 	atomic.StoreInt32((*int32)(&l.stringType), int32(v))
 }
 
+// SetCaller sets the func the logger uses to get the file name and line
+// number of the caller of each log line, e.g. a func returning a fixed caller
+// for deterministic tests. A nil caller restores the use of runtime.Caller.
+func (l *Logger) SetCaller(caller func() (file string, line int)) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.caller = caller
+}
+
 func (l *Logger) levelString(i Level) string {
-	v := LevelStringType(atomic.LoadInt32(&l.stringType))
+	names := levelNames(LevelStringType(atomic.LoadInt32(&l.stringType)))
+	if names == nil {
+		// unknown level results in an empty string
+		return ""
+	}
+	return names[i]
+}
+
+// levelNames returns the level strings of the LevelStringType v; nil is
+// returned for unknown types.
+func levelNames(v LevelStringType) []string {
 	switch v {
 	case Full:
-		return levelFull[i]
+		return levelFull
 	case Char:
-		return levelChar[i]
+		return levelChar
 	case Short:
-		return levelShort[i]
+		return levelShort
+	}
+	return nil
+}
+
+// Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid
+// zero-padding.
+func itoa(buf *[]byte, i int, wid int) {
+	// Assemble decimal in reverse order.
+	var b [20]byte
+	bp := len(b) - 1
+	for i >= 10 || wid > 1 {
+		wid--
+		q := i / 10
+		b[bp] = byte('0' + i - q*10)
+		bp--
+		i = q
+	}
+	// i < 10
+	b[bp] = byte('0' + i)
+	*buf = append(*buf, b[bp:]...)
+}
+
+// formatHeader writes the log header to buf in the manner of log.Logger:
+//   - date and/or time (if corresponding flags are provided),
+//   - file and line number (if corresponding flags are provided).
+//
+// The prefix and the level string are written by the caller, depending on the
+// Lmsgprefix, Llevelfirst, and Llevelbeforeprefix flags.
+func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
+	l.appendTime(buf, t)
+	if l.flag&(Lshortfile|Llongfile) != 0 {
+		if l.flag&Lshortfile != 0 {
+			short := file
+			for i := len(file) - 1; i > 0; i-- {
+				if file[i] == '/' {
+					short = file[i+1:]
+					break
+				}
+			}
+			file = short
+		}
+		*buf = append(*buf, file...)
+		*buf = append(*buf, ':')
+		itoa(buf, line, -1)
+		*buf = append(*buf, ": "...)
+	}
+}
+
+// appendTime appends the timestamp to buf, followed by a space, if the logger
+// has a time format. Otherwise, it appends the date and/or time, each followed
+// by a space, if the corresponding flags are provided.
+func (l *Logger) appendTime(buf *[]byte, t time.Time) {
+	if l.location != nil {
+		t = t.In(l.location)
+	} else if l.flag&LUTC != 0 {
+		t = t.UTC()
+	}
+	if l.timeFormat != "" {
+		if l.timeFormat == TimeFormatUnixMilli {
+			*buf = strconv.AppendInt(*buf, t.UnixNano()/int64(time.Millisecond), 10)
+		} else {
+			*buf = t.AppendFormat(*buf, l.timeFormat)
+		}
+		*buf = append(*buf, ' ')
+		return
+	}
+	if l.flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		if l.flag&Ldate != 0 {
+			year, month, day := t.Date()
+			itoa(buf, year, 4)
+			*buf = append(*buf, '/')
+			itoa(buf, int(month), 2)
+			*buf = append(*buf, '/')
+			itoa(buf, day, 2)
+			*buf = append(*buf, ' ')
+		}
+		if l.flag&(Ltime|Lmicroseconds) != 0 {
+			hour, min, sec := t.Clock()
+			itoa(buf, hour, 2)
+			*buf = append(*buf, ':')
+			itoa(buf, min, 2)
+			*buf = append(*buf, ':')
+			itoa(buf, sec, 2)
+			if l.flag&Lmicroseconds != 0 {
+				*buf = append(*buf, '.')
+				itoa(buf, t.Nanosecond()/1e3, 6)
+			}
+			*buf = append(*buf, ' ')
+		}
+	}
+}
+
+// output writes the output for a log line of level lvl, 0 for lines without a
+// level. The line is written to the logger's output and to each additional
+// output whose level allows it. The msg contains the message; it is
+// written after the prefix and header specified by the flags of the Logger and
+// the level string, and is followed by the bound fields and a newline.
+// Outputs that are RecordWriters are given a Record instead. Calldepth is the
+// count of the number of frames to skip when computing the file name and line
+// number if Llongfile or Lshortfile is set, or if any output is a
+// RecordWriter; a value of 1 will print the details for the caller of output.
+func (l *Logger) output(lvl Level, calldepth int, msg []byte) error {
+	return l.outputFields(lvl, calldepth+1, l.fields, l.errFields, msg)
+}
+
+// outputFields is output for a line with the fields, instead of the logger's
+// fields, the last errFields of which describe an error; see Err.
+func (l *Logger) outputFields(lvl Level, calldepth int, fields []Field, errFields int, msg []byte) error {
+	var file string
+	var line int
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	now := l.now() // get this early.
+	if l.flag&(Lshortfile|Llongfile) != 0 || l.needsRecord() {
+		if l.caller != nil {
+			file, line = l.caller()
+		} else {
+			// release lock while getting caller info - it's expensive.
+			l.outMu.Unlock()
+			var ok bool
+			_, file, line, ok = runtime.Caller(calldepth)
+			if !ok {
+				file = "???"
+				line = 0
+			}
+			l.outMu.Lock()
+		}
 	}
-	// unknown level results in an empty string
-	return ""
+	fl := Logger{logger: l.logger, fields: fields, callDepth: l.callDepth, errFields: errFields}
+	return fl.writeLine(now, lvl, file, line, msg)
+}
+
+// outputAt writes a line of the logger's own, e.g. a rate limit summary, whose
+// caller is already known; hooks aren't fired for it.
+func (l *Logger) outputAt(lvl Level, file string, line int, msg []byte) error {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.internal = true
+	defer func() { l.internal = false }()
+	return l.writeLine(l.now(), lvl, file, line, msg)
+}
+
+// writeLine redacts the log line and writes it, unless it's a duplicate that
+// is collapsed; see output. The caller must hold outMu.
+func (l *Logger) writeLine(now time.Time, lvl Level, file string, line int, msg []byte) error {
+	if len(l.redactors) > 0 {
+		l, msg = l.redact(msg)
+	}
+	if l.dedup != nil && l.dedupLine(lvl, file, line, msg) {
+		return nil
+	}
+	return l.formatLine(now, lvl, file, line, msg)
+}
+
+// formatLine formats the log line and writes it to the logger's outputs. The
+// caller must hold outMu.
+func (l *Logger) formatLine(now time.Time, lvl Level, file string, line int, msg []byte) error {
+	l.buf = l.buf[:0]
+	l.cbuf = l.cbuf[:0]
+	l.levelStart, l.levelEnd = 0, 0
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+	if l.format == ConsoleFormat {
+		l.formatConsole(lvl, now, file, line, msg)
+	} else {
+		msgPrefix := l.flag&Lmsgprefix != 0
+		levelFirst := l.flag&Llevelfirst != 0
+		beforePrefix := l.flag&Llevelbeforeprefix != 0
+		if levelFirst && (beforePrefix || msgPrefix) {
+			l.appendLevel(lvl)
+		}
+		if !msgPrefix {
+			l.buf = append(l.buf, l.prefix...)
+		}
+		if levelFirst && !beforePrefix && !msgPrefix {
+			l.appendLevel(lvl)
+		}
+		l.formatHeader(&l.buf, now, file, line)
+		if !levelFirst && beforePrefix {
+			l.appendLevel(lvl)
+		}
+		if msgPrefix {
+			l.buf = append(l.buf, l.prefix...)
+		}
+		if !levelFirst && !beforePrefix {
+			l.appendLevel(lvl)
+		}
+		l.buf = append(l.buf, msg...)
+		l.buf = appendFields(l.buf, l.fields[:len(l.fields)-l.errFields])
+		l.buf = append(l.buf, '\n')
+		if l.errFields > 0 {
+			l.buf = appendErrorTrailer(l.buf, l.fields[len(l.fields)-l.errFields:], []byte{'\t'})
+		}
+	}
+	// only create the Record if there's a RecordWriter or Hook to give it to
+	var r *Record
+	var hookErrs []error
+	if l.needsRecord() {
+		r = &Record{Time: now, Level: lvl, Message: string(msg), Fields: l.fields, File: file, Line: line}
+		if len(l.hooks) > 0 && !l.internal {
+			hookErrs = l.fireHooks(r)
+		}
+	}
+	var err error
+	if out := l.outputFor(lvl); out != nil {
+		err = l.write(out, lvl, r)
+	}
+	for _, o := range l.outputs {
+		if !o.level.allows(lvl) {
+			continue
+		}
+		if werr := l.write(o.w, lvl, r); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if len(hookErrs) > 0 {
+		l.reportHookErrors(r, hookErrs)
+	}
+	return err
+}
+
+// appendLevel appends the level string of lvl, followed by a space, to l.buf
+// and records where it is for coloring. Nothing is appended for lines without
+// a level. The caller must hold outMu.
+func (l *Logger) appendLevel(lvl Level) {
+	if lvl == 0 {
+		return
+	}
+	l.levelStart = len(l.buf)
+	l.buf = append(l.buf, l.levelString(lvl)...)
+	l.levelEnd = len(l.buf)
+	l.buf = append(l.buf, ' ')
+}
+
+// needsRecord reports whether a Record is needed for each line, i.e. the
+// logger has a RecordWriter or a Hook. The caller must hold outMu.
+func (l *Logger) needsRecord() bool {
+	return len(l.hooks) > 0 || l.hasRecordWriter()
+}
+
+// hasRecordWriter reports whether any of the logger's outputs is a
+// RecordWriter. The caller must hold outMu.
+func (l *Logger) hasRecordWriter() bool {
+	if _, ok := l.out.(RecordWriter); ok {
+		return true
+	}
+	for _, w := range l.levelOuts {
+		if _, ok := w.(RecordWriter); ok {
+			return true
+		}
+	}
+	for _, o := range l.outputs {
+		if _, ok := o.w.(RecordWriter); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// write writes the formatted line in l.buf, of level lvl, to w, with its
+// level string colored if w is colored, or, if w is a RecordWriter, writes r
+// to w; r is only set if the logger has a RecordWriter. The caller must hold
+// outMu.
+func (l *Logger) write(w io.Writer, lvl Level, r *Record) error {
+	if rw, ok := w.(RecordWriter); ok {
+		return rw.WriteRecord(r)
+	}
+	b := l.buf
+	if l.levelEnd > l.levelStart && l.colorize(w) {
+		b = l.coloredLine(lvl)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// allows reports whether a line of level v is written to an output whose level
+// is l. Lines without a level, 0, and Fatal and Panic lines are written to all
+// outputs whose level isn't LogNone.
+func (l Level) allows(v Level) bool {
+	if l <= LogNone {
+		return false
+	}
+	if v == 0 || v >= logFatal {
+		return true
+	}
+	return v <= l
 }
 
 var std *Logger
 
 func init() {
-	std = New(LogError, Full, os.Stderr, "", log.LstdFlags)
+	std = New(LogError, Full, os.Stderr, "", LstdFlags)
 	std.callDepth = 3
 }
 
@@ -613,12 +1196,12 @@ func Println(v ...interface{}) {
 
 // Flags returns the standard logger's output flags.
 func Flags() int {
-	return std.l.Flags()
+	return std.Flags()
 }
 
 // SetFlags sets the standard logger's flags.
 func SetFlags(flags int) {
-	std.l.SetFlags(flags)
+	std.SetFlags(flags)
 }
 
 // GetLevel returns the standard logger's level.
@@ -638,14 +1221,27 @@ func SetOutput(w io.Writer) {
 	std.SetOutput(w)
 }
 
+// SetLevelOutput sets the standard logger's output for the lines of level
+// lvl.
+func SetLevelOutput(lvl Level, w io.Writer) {
+	std.SetLevelOutput(lvl, w)
+}
+
+// AddOutput adds an additional output destination to the standard logger.
+// Lines are written to w only if they pass both the standard logger's level
+// and the output's level.
+func AddOutput(w io.Writer, level Level) {
+	std.AddOutput(w, level)
+}
+
 // Prefix returns the standrd logger's prefix.
 func Prefix() string {
-	return std.l.Prefix()
+	return std.Prefix()
 }
 
 // SetPrefix sets the standard logger's prefix.
 func SetPrefix(prefix string) {
-	std.l.SetPrefix(prefix)
+	std.SetPrefix(prefix)
 }
 
 // GetLevelStringType returns what the standard logger is using for the error
@@ -659,3 +1255,9 @@ func GetLevelStringType() LevelStringType {
 func SetLevelStringType(v LevelStringType) {
 	std.SetLevelStringType(v)
 }
+
+// SetCaller sets the func the standard logger uses to get the file name and
+// line number of the caller of each log line.
+func SetCaller(caller func() (file string, line int)) {
+	std.SetCaller(caller)
+}
@@ -115,6 +115,12 @@ func TestParseFlag(t *testing.T) {
 		{"UTC", LUTC, nil},
 		{"lstdflags", LstdFlags, nil},
 		{"stdflags", LstdFlags, nil},
+		{"lmsgprefix", Lmsgprefix, nil},
+		{"MsgPrefix", Lmsgprefix, nil},
+		{"llevelfirst", Llevelfirst, nil},
+		{"LevelFirst", Llevelfirst, nil},
+		{"llevelbeforeprefix", Llevelbeforeprefix, nil},
+		{"levelbeforeprefix", Llevelbeforeprefix, nil},
 		{"none", 0, nil},
 	}
 
@@ -394,6 +400,35 @@ func TestUseCharFlagsPrefix(t *testing.T) {
 	if buf.String() != "xyzDBG: debugln: 42\n" {
 		t.Errorf("write debugln line: %q; want \"xyzDEBUG: debugln: 42\n\"", buf.String())
 	}
+	// prefix and level string placement
+	l.SetPrefix("[xyz] ")
+	tests := []struct {
+		flag     int
+		expected string
+	}{
+		{Lshortfile, "[xyz] ezlog_test.go:421: DBG: debug\n"},
+		{Lshortfile | Llevelbeforeprefix, "[xyz] ezlog_test.go:421: DBG: debug\n"},
+		{Lshortfile | Lmsgprefix, "ezlog_test.go:421: [xyz] DBG: debug\n"},
+		{Lshortfile | Lmsgprefix | Llevelbeforeprefix, "ezlog_test.go:421: DBG: [xyz] debug\n"},
+		{Lshortfile | Llevelfirst, "[xyz] DBG: ezlog_test.go:421: debug\n"},
+		{Lshortfile | Llevelfirst | Llevelbeforeprefix, "DBG: [xyz] ezlog_test.go:421: debug\n"},
+		{Lshortfile | Llevelfirst | Lmsgprefix, "DBG: ezlog_test.go:421: [xyz] debug\n"},
+		{Lshortfile | Llevelfirst | Lmsgprefix | Llevelbeforeprefix, "DBG: ezlog_test.go:421: [xyz] debug\n"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		l.SetFlags(test.flag)
+		l.Debug("debug")
+		if buf.String() != test.expected {
+			t.Errorf("%v: got %q; want %q", FlagNames(test.flag), buf.String(), test.expected)
+		}
+	}
+	buf.Reset()
+	l.SetFlags(Lmsgprefix | Llevelfirst)
+	l.Print("print")
+	if buf.String() != "[xyz] print\n" {
+		t.Errorf("write print line: %q; want \"[xyz] print\n\"", buf.String())
+	}
 }
 
 // This also tests the package global logger
@@ -585,3 +620,174 @@ func TestUnknownFlagError(t *testing.T) {
 		t.Errorf("got %q; want \"unknown log flag: vogons", err)
 	}
 }
+
+func TestAddOutput(t *testing.T) {
+	var buf, errBuf, noneBuf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0)
+	l.AddOutput(&errBuf, LogError)
+	l.AddOutput(&noneBuf, LogNone)
+	l.Error("error")
+	l.Info("info")
+	l.Debug("debug")
+	l.Print("print")
+	if buf.String() != "ERROR: error\nINFO: info\nprint\n" {
+		t.Errorf("output: got %q; want \"ERROR: error\\nINFO: info\\nprint\\n\"", buf.String())
+	}
+	if errBuf.String() != "ERROR: error\nprint\n" {
+		t.Errorf("error output: got %q; want \"ERROR: error\\nprint\\n\"", errBuf.String())
+	}
+	if noneBuf.Len() > 0 {
+		t.Errorf("none output: expected no bytes to be written, %d were", noneBuf.Len())
+	}
+	buf.Reset()
+	errBuf.Reset()
+	l.SetOutput(nil)
+	l.Info("info")
+	l.Error("error")
+	if errBuf.String() != "ERROR: error\n" {
+		t.Errorf("error output: got %q; want \"ERROR: error\\n\"", errBuf.String())
+	}
+}
+
+func TestLevelAllows(t *testing.T) {
+	tests := []struct {
+		out      Level
+		line     Level
+		expected bool
+	}{
+		{LogNone, 0, false},
+		{LogNone, LogError, false},
+		{LogNone, logFatal, false},
+		{LogError, 0, true},
+		{LogError, LogError, true},
+		{LogError, LogInfo, false},
+		{LogError, logFatal, true},
+		{LogError, logPanic, true},
+		{LogInfo, LogInfo, true},
+		{LogInfo, LogDebug, false},
+		{LogDebug, LogDebug, true},
+	}
+	for _, test := range tests {
+		if v := test.out.allows(test.line); v != test.expected {
+			t.Errorf("%s allows %d: got %v; want %v", test.out, test.line, v, test.expected)
+		}
+	}
+}
+
+func TestLevelStringTypeByName(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  LevelStringType
+		ok   bool
+	}{
+		{"", Full, true},
+		{"full", Full, true},
+		{"FULL", Full, true},
+		{"Short", Short, true},
+		{"char", Char, true},
+		{"c", 0, false},
+	}
+	for _, test := range tests {
+		v, ok := LevelStringTypeByName(test.name)
+		if ok != test.ok {
+			t.Errorf("%s: got %v; want %v", test.name, ok, test.ok)
+		}
+		if v != test.typ {
+			t.Errorf("%s: got %v; want %v", test.name, v, test.typ)
+		}
+	}
+}
+
+func TestFlagNames(t *testing.T) {
+	tests := []struct {
+		flag     int
+		expected string
+	}{
+		{0, "[none]"},
+		{LstdFlags, "[date time]"},
+		{Lmicroseconds | Lshortfile | LUTC, "[microseconds shortfile utc]"},
+		{Llongfile, "[longfile]"},
+		{Lmsgprefix | Llevelfirst | Llevelbeforeprefix, "[msgprefix levelfirst levelbeforeprefix]"},
+	}
+	for _, test := range tests {
+		names := FlagNames(test.flag)
+		if fmt.Sprint(names) != test.expected {
+			t.Errorf("%d: got %v; want %s", test.flag, names, test.expected)
+		}
+		f := 0
+		for _, name := range names {
+			v, err := ParseFlag(name)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", name, err)
+			}
+			f |= v
+		}
+		if f != test.flag {
+			t.Errorf("parse %v: got %d; want %d", names, f, test.flag)
+		}
+	}
+}
+
+func TestLevelStringTypeString(t *testing.T) {
+	tests := []struct {
+		LevelStringType
+		expected string
+	}{
+		{Full, "full"},
+		{Short, "short"},
+		{Char, "char"},
+		{42, "LevelStringType(42)"},
+	}
+	for _, test := range tests {
+		if test.LevelStringType.String() != test.expected {
+			t.Errorf("got %q; want %q", test.LevelStringType.String(), test.expected)
+		}
+	}
+}
+
+func TestSetCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", Llongfile)
+	l.SetCaller(func() (string, int) { return "/a/b/c.go", 42 })
+	l.Info("fixed")
+	l.SetCaller(nil)
+	l.SetFlags(0)
+	l.Info("none")
+	if buf.String() != "/a/b/c.go:42: INFO: fixed\nINFO: none\n" {
+		t.Errorf("got %q; want %q", buf.String(), "/a/b/c.go:42: INFO: fixed\nINFO: none\n")
+	}
+}
+
+func TestSetLevelOutput(t *testing.T) {
+	var out, stderr, stdout bytes.Buffer
+	var rw recordBuffer
+	l := New(LogDebug, Short, &out, "app ", Lmsgprefix)
+	for _, lvl := range []Level{LogError, LogFatal, LogPanic} {
+		l.SetLevelOutput(lvl, &stderr)
+	}
+	l.SetLevelOutput(LogInfo, &stdout)
+	l.SetLevelOutput(LogDebug, &rw)
+	l.SetLevelOutput(42, &stdout) // ignored
+	l.Error("a")
+	l.Info("b")
+	l.Debug("c")
+	l.Print("d")
+	func() {
+		defer func() { recover() }()
+		l.Panic("e")
+	}()
+	l.SetLevelOutput(LogInfo, nil)
+	l.Info("f")
+	if stderr.String() != "app ERR: a\napp PANIC: e\n" {
+		t.Errorf("stderr: got %q; want %q", stderr.String(), "app ERR: a\napp PANIC: e\n")
+	}
+	if stdout.String() != "app INF: b\n" {
+		t.Errorf("stdout: got %q; want %q", stdout.String(), "app INF: b\n")
+	}
+	if out.String() != "app d\napp INF: f\n" {
+		t.Errorf("out: got %q; want %q", out.String(), "app d\napp INF: f\n")
+	}
+	if len(rw.records) != 1 || rw.records[0].Message != "c" {
+		t.Errorf("records: got %v; want the debug line", rw.records)
+	}
+}
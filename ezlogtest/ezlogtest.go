@@ -0,0 +1,191 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ezlogtest provides helpers for testing code that logs with ezlog.
+//
+// A Logger records the Record of each line that is written to it, so that
+// tests can make assertions about what was logged instead of matching the
+// formatted output:
+//
+//	l := ezlogtest.New(t, ezlog.LogDebug)
+//	doSomething(l.Logger)
+//	rec := l.RequireLogged(ezlog.LogError, "connection refused")
+//
+// The lines are also written to the test's log, see TBWriter, so that they
+// are shown with the test that wrote them when it fails or when tests are run
+// with -v.
+package ezlogtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mohae/ezlog"
+)
+
+// Recorder is an ezlog.RecordWriter that keeps the Records written to it.
+// This is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	records []ezlog.Record
+}
+
+// Write records p as a Record without a level.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, ezlog.Record{Message: strings.TrimSuffix(string(p), "\n")})
+	return len(p), nil
+}
+
+// WriteRecord records a copy of rec.
+func (r *Recorder) WriteRecord(rec *ezlog.Record) error {
+	c := *rec
+	c.Fields = append([]ezlog.Field(nil), rec.Fields...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, c)
+	return nil
+}
+
+// Records returns a copy of the recorded Records, in the order they were
+// written.
+func (r *Recorder) Records() []ezlog.Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ezlog.Record(nil), r.records...)
+}
+
+// Reset discards the recorded Records.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
+
+// Logger is an ezlog.Logger that records the Record of each line that is
+// written to it. Loggers created from it with With record to the same
+// Recorder.
+type Logger struct {
+	*ezlog.Logger
+	rec *Recorder
+	tb  testing.TB
+}
+
+// New returns a Logger with the level lvl for the test tb. Each line is
+// recorded and written to tb's log with the caller's file name and line
+// number.
+func New(tb testing.TB, lvl ezlog.Level) *Logger {
+	r := &Recorder{}
+	l := ezlog.New(lvl, ezlog.Full, r, "", 0)
+	l.AddOutput(NewTBWriter(tb), ezlog.LogDebug)
+	l.SetFlags(ezlog.Lshortfile)
+	return &Logger{Logger: l, rec: r, tb: tb}
+}
+
+// Records returns a copy of the recorded Records, in the order they were
+// written.
+func (l *Logger) Records() []ezlog.Record {
+	return l.rec.Records()
+}
+
+// Reset discards the recorded Records.
+func (l *Logger) Reset() {
+	l.rec.Reset()
+}
+
+// Find returns the first recorded Record of level lvl whose message contains
+// substr; 0 is the level of lines without a level, e.g. Print lines. False is
+// returned if there is no such Record.
+func (l *Logger) Find(lvl ezlog.Level, substr string) (ezlog.Record, bool) {
+	for _, r := range l.Records() {
+		if r.Level == lvl && strings.Contains(r.Message, substr) {
+			return r, true
+		}
+	}
+	return ezlog.Record{}, false
+}
+
+// RequireLogged fails the test, with tb.Fatalf, unless a line of level lvl
+// whose message contains substr was logged. The first such line's Record is
+// returned.
+func (l *Logger) RequireLogged(lvl ezlog.Level, substr string) ezlog.Record {
+	l.tb.Helper()
+	r, ok := l.Find(lvl, substr)
+	if !ok {
+		l.tb.Fatalf("no %s line containing %q was logged; logged:\n%s", levelName(lvl), substr, l.dump())
+	}
+	return r
+}
+
+// RequireNotLogged fails the test, with tb.Fatalf, if a line of level lvl
+// whose message contains substr was logged.
+func (l *Logger) RequireNotLogged(lvl ezlog.Level, substr string) {
+	l.tb.Helper()
+	r, ok := l.Find(lvl, substr)
+	if ok {
+		l.tb.Fatalf("unexpected %s line containing %q was logged: %s:%d: %s", levelName(lvl), substr, r.File, r.Line, r.Message)
+	}
+}
+
+// dump returns the recorded Records, one per line.
+func (l *Logger) dump() string {
+	var b strings.Builder
+	for _, r := range l.Records() {
+		fmt.Fprintf(&b, "\t%s:%d: %s: %s\n", r.File, r.Line, levelName(r.Level), r.Message)
+	}
+	if b.Len() == 0 {
+		return "\t(nothing)\n"
+	}
+	return b.String()
+}
+
+func levelName(lvl ezlog.Level) string {
+	if lvl == 0 {
+		return "unleveled"
+	}
+	return lvl.String()
+}
+
+// TBWriter is an io.Writer that writes each line to a test's log with
+// tb.Log. Lines that are written after the test has completed are dropped:
+// tb.Log panics once a test has completed, which would otherwise happen when
+// goroutines started by a test outlive it.
+type TBWriter struct {
+	tb   testing.TB
+	mu   sync.Mutex // protects done
+	done bool
+}
+
+// NewTBWriter returns a TBWriter that writes to tb's log.
+func NewTBWriter(tb testing.TB) *TBWriter {
+	w := &TBWriter{tb: tb}
+	tb.Cleanup(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.done = true
+	})
+	return w
+}
+
+// Write writes p, without its trailing newline, to the test's log.
+func (w *TBWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.tb.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
@@ -0,0 +1,109 @@
+package ezlogtest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mohae/ezlog"
+)
+
+// fakeTB records the calls to Log and Fatalf instead of logging and failing.
+type fakeTB struct {
+	testing.TB
+	logs     []string
+	fatal    string
+	errorf   string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Log(args ...interface{}) {
+	tb.logs = append(tb.logs, fmt.Sprint(args...))
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...interface{}) {
+	tb.fatal = fmt.Sprintf(format, args...)
+}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errorf = fmt.Sprintf(format, args...)
+}
+
+func (tb *fakeTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+func TestLogger(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	l := New(tb, ezlog.LogInfo)
+	_, file, line, _ := runtime.Caller(0)
+	l.With("user", "arthur").Errorf("can't find towel: %d", 42)
+	l.Info("info")
+	l.Debug("debug")
+	l.Print("print")
+
+	r := l.RequireLogged(ezlog.LogError, "towel")
+	if tb.fatal != "" {
+		t.Fatalf("unexpected failure: %s", tb.fatal)
+	}
+	if r.Message != "can't find towel: 42" {
+		t.Errorf("message: got %q; want %q", r.Message, "can't find towel: 42")
+	}
+	if r.File != file || r.Line != line+1 {
+		t.Errorf("caller: got %s:%d; want %s:%d", r.File, r.Line, file, line+1)
+	}
+	if len(r.Fields) != 1 || r.Fields[0] != (ezlog.Field{Key: "user", Value: "arthur"}) {
+		t.Errorf("fields: got %v; want [{user arthur}]", r.Fields)
+	}
+	l.RequireLogged(ezlog.LogInfo, "info")
+	l.RequireLogged(0, "print")
+	l.RequireNotLogged(ezlog.LogDebug, "debug")
+	if tb.fatal != "" {
+		t.Fatalf("unexpected failure: %s", tb.fatal)
+	}
+	if len(l.Records()) != 3 {
+		t.Errorf("records: got %d; want 3", len(l.Records()))
+	}
+
+	l.RequireLogged(ezlog.LogInfo, "towel")
+	if !strings.Contains(tb.fatal, `no INFO line containing "towel"`) || !strings.Contains(tb.fatal, "can't find towel") {
+		t.Errorf("got failure %q; want one listing the logged lines", tb.fatal)
+	}
+	tb.fatal = ""
+	l.RequireNotLogged(ezlog.LogError, "towel")
+	if !strings.Contains(tb.fatal, `unexpected ERROR line containing "towel"`) {
+		t.Errorf("got failure %q; want an unexpected ERROR line failure", tb.fatal)
+	}
+
+	l.Reset()
+	if len(l.Records()) != 0 {
+		t.Errorf("records after reset: got %d; want 0", len(l.Records()))
+	}
+}
+
+func TestTBWriter(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	l := ezlog.New(ezlog.LogInfo, ezlog.Full, NewTBWriter(tb), "", 0)
+	l.Info("one")
+	for _, f := range tb.cleanups {
+		f()
+	}
+	l.Info("two")
+	if len(tb.logs) != 1 || tb.logs[0] != "INFO: one" {
+		t.Errorf("got %q; want [\"INFO: one\"]", tb.logs)
+	}
+}
+
+func TestNewLogsToTB(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	l := New(tb, ezlog.LogInfo)
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("hello")
+	expected := fmt.Sprintf("ezlogtest_test.go:%d: INFO: hello", line+1)
+	if len(tb.logs) != 1 || tb.logs[0] != expected {
+		t.Errorf("got %q; want [%q]", tb.logs, expected)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlogtest
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohae/ezlog"
+)
+
+// update is namespaced so that it doesn't conflict with an -update flag
+// defined by the test package that uses Golden.
+var update = flag.Bool("ezlogtest.update", false, "update the ezlogtest golden files")
+
+// The clock and caller that Golden renders the lines with.
+var (
+	GoldenTime = time.Date(2009, time.November, 10, 23, 4, 5, 123456789, time.UTC)
+	GoldenFile = "/go/src/example.com/app/main.go"
+	GoldenLine = 42
+)
+
+// Golden renders a fixed set of log lines through l and compares the output
+// with the golden file testdata/name.golden. The test fails, with tb.Errorf,
+// if they differ. When the tests are run with the -ezlogtest.update flag, the
+// golden file is written instead:
+//
+//	go test -run TestFormat -ezlogtest.update
+//
+// The lines are rendered with l's configuration: its level, level string
+// type, prefix, flags, format, and so on. To make the output deterministic,
+// Golden sets l's output, clock, and caller; the clock returns GoldenTime and
+// the caller returns GoldenFile and GoldenLine. l should be a Logger that is
+// only used for the comparison.
+func Golden(tb testing.TB, name string, l *ezlog.Logger) {
+	tb.Helper()
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	l.SetClock(func() time.Time { return GoldenTime })
+	l.SetCaller(func() (string, int) { return GoldenFile, GoldenLine })
+	renderGolden(l)
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		err := os.MkdirAll("testdata", 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+		}
+		if err != nil {
+			tb.Fatalf("update golden file: %s", err)
+		}
+		return
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("%s; run the tests with -ezlogtest.update to create it", err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		tb.Errorf("%s: output differs from the golden file; run the tests with -ezlogtest.update to update it\ngot:\n%s\nwant:\n%s", path, indent(buf.String()), indent(string(b)))
+	}
+}
+
+// renderGolden writes the golden lines to l.
+func renderGolden(l *ezlog.Logger) {
+	l.Error("error")
+	l.Errorf("errorf: %d%% of %s", 42, "everything")
+	l.Errorln("errorln:", 42)
+	l.Info("info")
+	l.Infof("infof: %q", "quoted")
+	l.Infoln("infoln:", 42)
+	l.Debug("debug")
+	l.Debugf("debugf: %v", []int{1, 2, 3})
+	l.Debugln("debugln:", 42)
+	l.Print("print")
+	l.Printf("printf: %d", 42)
+	l.Println("println:", 42)
+	l.Info("multi-line message\nsecond line\nthird line")
+	l.Info("")
+	fl := l.With("user", "arthur", "answer", 42, "quote", "don't panic", "empty", "")
+	fl.Error("with fields")
+	fl.Info("with fields")
+	fl.Debug("with fields")
+	fl.Print("with fields")
+	fl.Info("multi-line message with fields\nsecond line")
+}
+
+// indent indents each line of s with a tab.
+func indent(s string) string {
+	return "\t" + strings.Replace(strings.TrimSuffix(s, "\n"), "\n", "\n\t", -1)
+}
@@ -0,0 +1,71 @@
+package ezlogtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mohae/ezlog"
+)
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		l    func() *ezlog.Logger
+	}{
+		{"text", func() *ezlog.Logger {
+			return ezlog.New(ezlog.LogDebug, ezlog.Full, nil, "[svc] ", ezlog.LstdFlags|ezlog.Lshortfile)
+		}},
+		{"text_info_short_msgprefix", func() *ezlog.Logger {
+			return ezlog.New(ezlog.LogInfo, ezlog.Short, nil, "[svc] ", ezlog.Lmicroseconds|ezlog.Llongfile|ezlog.Lmsgprefix)
+		}},
+		{"text_rfc3339_char_levelfirst", func() *ezlog.Logger {
+			l := ezlog.New(ezlog.LogDebug, ezlog.Char, nil, "[svc] ", ezlog.Llevelfirst|ezlog.Llevelbeforeprefix)
+			l.SetTimeFormat(time.RFC3339Nano)
+			l.SetLocation(time.FixedZone("EST", -5*60*60))
+			return l
+		}},
+		{"console", func() *ezlog.Logger {
+			l := ezlog.New(ezlog.LogDebug, ezlog.Full, nil, "", ezlog.Ltime|ezlog.Lshortfile)
+			l.SetFormat(ezlog.ConsoleFormat)
+			return l
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Golden(t, test.name, test.l())
+		})
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	if *update {
+		t.Skip("updating golden files")
+	}
+	dir, err := ioutil.TempDir("", "ezlogtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	os.Mkdir("testdata", 0755)
+	err = ioutil.WriteFile(filepath.Join("testdata", "x.golden"), []byte("something else\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tb := &fakeTB{TB: t}
+	Golden(tb, "x", ezlog.New(ezlog.LogDebug, ezlog.Full, nil, "", 0))
+	if tb.errorf == "" {
+		t.Error("expected the mismatch to fail the test")
+	}
+}
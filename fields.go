@@ -0,0 +1,104 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A Field is a key/value pair that is added to log lines.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// With returns a Logger with the keyvals bound to it as fields, in addition to
+// any fields that are already bound to l. The keyvals are alternating keys and
+// values; keys that aren't strings are converted using fmt.Sprint and a key
+// without a value gets the value "(MISSING)".
+//
+// The returned Logger shares everything else with l: its level, flags,
+// prefix, outputs, and funcs; changing any of these on one changes them on the
+// other.
+//
+// Fields are written after the message, as key=value pairs, and are included
+// in the Record given to RecordWriters.
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	fields := make([]Field, 0, len(l.fields)+(len(keyvals)+1)/2)
+	fields = append(fields, l.fields...)
+	fields = appendKeyvals(fields, keyvals)
+	return &Logger{logger: l.logger, fields: fields, callDepth: 2}
+}
+
+// Fields returns a copy of the fields bound to l.
+func (l *Logger) Fields() []Field {
+	return append([]Field(nil), l.fields...)
+}
+
+// appendKeyvals appends the keyvals to fields as Fields.
+func appendKeyvals(fields []Field, keyvals []interface{}) []Field {
+	for i := 0; i < len(keyvals); i += 2 {
+		k, ok := keyvals[i].(string)
+		if !ok {
+			k = fmt.Sprint(keyvals[i])
+		}
+		var v interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		fields = append(fields, Field{Key: k, Value: v})
+	}
+	return fields
+}
+
+// appendFields appends the fields to buf as space separated key=value pairs,
+// each preceded by a space. Values that contain spaces, quotes, or '=' are
+// quoted.
+func appendFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		var v string
+		switch x := f.Value.(type) {
+		case string:
+			v = x
+		case int:
+			buf = strconv.AppendInt(buf, int64(x), 10)
+			continue
+		case int64:
+			buf = strconv.AppendInt(buf, x, 10)
+			continue
+		case bool:
+			buf = strconv.AppendBool(buf, x)
+			continue
+		default:
+			v = fmt.Sprint(x)
+		}
+		if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+			buf = strconv.AppendQuote(buf, v)
+			continue
+		}
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+// With returns a Logger that shares the standard logger's settings and has the
+// keyvals bound to it as fields. See Logger.With.
+func With(keyvals ...interface{}) *Logger {
+	return std.With(keyvals...)
+}
@@ -0,0 +1,73 @@
+package ezlog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0)
+	a := l.With("user", "arthur", "id", 42)
+	b := a.With("planet", "magrathea", 3)
+	a.Info("a")
+	if buf.String() != "INFO: a user=arthur id=42\n" {
+		t.Errorf("got %q; want \"INFO: a user=arthur id=42\\n\"", buf.String())
+	}
+	buf.Reset()
+	b.Errorln("b")
+	if buf.String() != "ERROR: b user=arthur id=42 planet=magrathea 3=(MISSING)\n" {
+		t.Errorf("got %q; want \"ERROR: b user=arthur id=42 planet=magrathea 3=(MISSING)\\n\"", buf.String())
+	}
+	buf.Reset()
+	l.Info("l")
+	if buf.String() != "INFO: l\n" {
+		t.Errorf("got %q; want \"INFO: l\\n\"", buf.String())
+	}
+	expected := []Field{{"user", "arthur"}, {"id", 42}}
+	if f := a.Fields(); !reflect.DeepEqual(f, expected) {
+		t.Errorf("fields: got %v; want %v", f, expected)
+	}
+
+	// settings are shared
+	b.SetLevel(LogError)
+	if l.GetLevel() != LogError {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogError)
+	}
+	buf.Reset()
+	a.Info("a")
+	if buf.Len() > 0 {
+		t.Errorf("expected no bytes to be written, %d were", buf.Len())
+	}
+}
+
+func TestWithFilename(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", Lshortfile).With("k", "v")
+	l.Info("x")
+	if buf.String() != "fields_test.go:48: INFO: x k=v\n" {
+		t.Errorf("got %q; want \"fields_test.go:48: INFO: x k=v\\n\"", buf.String())
+	}
+}
+
+func TestAppendFields(t *testing.T) {
+	tests := []struct {
+		fields   []Field
+		expected string
+	}{
+		{nil, ""},
+		{[]Field{{"a", 1}, {"b", true}}, " a=1 b=true"},
+		{[]Field{{"s", "two words"}}, ` s="two words"`},
+		{[]Field{{"s", ""}}, ` s=""`},
+		{[]Field{{"s", "a=b"}}, ` s="a=b"`},
+		{[]Field{{"s", "a\nb"}}, ` s="a\nb"`},
+		{[]Field{{"n", nil}}, " n=<nil>"},
+	}
+	for _, test := range tests {
+		v := string(appendFields(nil, test.fields))
+		if v != test.expected {
+			t.Errorf("got %q; want %q", v, test.expected)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Format is the layout of the log lines.
+type Format int
+
+const (
+	// TextFormat is the layout of stdlib's log.Logger, with the level
+	// string before the message and the fields after it:
+	//	prefix 2009/01/23 01:23:23 d.go:23: ERROR: message key=value
+	TextFormat Format = iota
+	// ConsoleFormat is a layout for reading logs in a terminal. The time,
+	// level, caller, and message are in aligned columns and the fields are
+	// to the right of the message:
+	//	prefix 2009/01/23 01:23:23 ERROR  c/d.go:23    message   key=value
+	// The levels are padded to the width of the longest level string, the
+	// caller is abbreviated to the file's directory and name, and the lines
+	// of multi-line messages are indented under the first line. The prefix
+	// is moved to before the message by Lmsgprefix; Llevelfirst and
+	// Llevelbeforeprefix are ignored.
+	ConsoleFormat
+)
+
+// Console format column widths. Columns are padded to their width; longer
+// values push the rest of the line to the right.
+const (
+	consoleCallerWidth  = 20
+	consoleMessageWidth = 40
+)
+
+var formatName = []string{
+	TextFormat:    "text",
+	ConsoleFormat: "console",
+}
+
+// String returns the name of the Format; the name can be parsed with
+// FormatByName.
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatName) {
+		return "Format(" + strconv.Itoa(int(f)) + ")"
+	}
+	return formatName[f]
+}
+
+// FormatByName gets the Format corresponding to s. A false will be returned
+// if s doesn't match any Formats. S is lower-cased prior to evaluation.
+//
+// Supported values:
+//
+//	TextFormat:     text, empty string ("")
+//	ConsoleFormat:  console
+func FormatByName(s string) (f Format, ok bool) {
+	switch strings.ToLower(s) {
+	case "", "text":
+		return TextFormat, true
+	case "console":
+		return ConsoleFormat, true
+	}
+	return 0, false
+}
+
+// GetFormat returns the logger's format.
+func (l *Logger) GetFormat() Format {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.format
+}
+
+// SetFormat sets the layout of the logger's log lines. The format doesn't
+// affect the Records given to RecordWriters.
+func (l *Logger) SetFormat(f Format) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.format = f
+}
+
+// formatConsole writes the log line to l.buf in ConsoleFormat. The caller
+// must hold outMu.
+func (l *Logger) formatConsole(lvl Level, t time.Time, file string, line int, msg []byte) {
+	if l.flag&Lmsgprefix == 0 {
+		l.buf = append(l.buf, l.prefix...)
+	}
+	l.appendTime(&l.buf, t)
+	// an unknown level string type has no level column, as in TextFormat.
+	if names := levelNames(LevelStringType(atomic.LoadInt32(&l.stringType))); names != nil {
+		width := 0
+		for _, name := range names[LogError:] {
+			if n := len(strings.TrimSuffix(name, ":")); n > width {
+				width = n
+			}
+		}
+		start := len(l.buf)
+		if lvl != 0 {
+			l.levelStart = start
+			l.buf = append(l.buf, strings.TrimSuffix(names[lvl], ":")...)
+			l.levelEnd = len(l.buf)
+		}
+		l.buf = appendPadding(l.buf, width-(len(l.buf)-start)+1)
+	}
+	if l.flag&(Lshortfile|Llongfile) != 0 {
+		start := len(l.buf)
+		l.buf = append(l.buf, abbreviateCaller(file)...)
+		l.buf = append(l.buf, ':')
+		itoa(&l.buf, line, -1)
+		l.buf = appendPadding(l.buf, consoleCallerWidth-(len(l.buf)-start))
+		l.buf = append(l.buf, ' ')
+	}
+	indent := len(l.buf)
+	if l.flag&Lmsgprefix != 0 {
+		l.buf = append(l.buf, l.prefix...)
+	}
+	first, rest := msg, []byte(nil)
+	if i := bytes.IndexByte(msg, '\n'); i >= 0 {
+		first, rest = msg[:i], msg[i+1:]
+	}
+	l.buf = append(l.buf, first...)
+	if fields := l.fields[:len(l.fields)-l.errFields]; len(fields) > 0 {
+		l.buf = appendPadding(l.buf, consoleMessageWidth-utf8.RuneCount(first))
+		l.buf = appendFields(l.buf, fields)
+	}
+	l.buf = append(l.buf, '\n')
+	for len(rest) > 0 {
+		var s []byte
+		s, rest = rest, nil
+		if i := bytes.IndexByte(s, '\n'); i >= 0 {
+			s, rest = s[:i], s[i+1:]
+		}
+		l.buf = appendPadding(l.buf, indent)
+		l.buf = append(l.buf, s...)
+		l.buf = append(l.buf, '\n')
+	}
+	if l.errFields > 0 {
+		l.buf = appendErrorTrailer(l.buf, l.fields[len(l.fields)-l.errFields:], bytes.Repeat([]byte{' '}, indent))
+	}
+}
+
+// appendPadding appends n spaces to buf.
+func appendPadding(buf []byte, n int) []byte {
+	for ; n > 0; n-- {
+		buf = append(buf, ' ')
+	}
+	return buf
+}
+
+// abbreviateCaller returns the final directory and file name elements of
+// file.
+func abbreviateCaller(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i <= 0 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+		return file[j+1:]
+	}
+	return file
+}
+
+// GetFormat returns the standard logger's format.
+func GetFormat() Format {
+	return std.GetFormat()
+}
+
+// SetFormat sets the layout of the standard logger's log lines.
+func SetFormat(f Format) {
+	std.SetFormat(f)
+}
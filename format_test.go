@@ -0,0 +1,137 @@
+package ezlog
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestConsoleFormat(t *testing.T) {
+	tests := []struct {
+		typ      LevelStringType
+		prefix   string
+		fields   []interface{}
+		expected string
+	}{
+		{Full, "", nil, "ERROR a\nINFO  b\nDEBUG c\n      d\n"},
+		{Short, "", nil, "ERR   a\nINF   b\nDBG   c\n      d\n"},
+		{Char, "pfx ", nil, "pfx E a\npfx I b\npfx D c\npfx   d\n"},
+		{Char, "", []interface{}{"k", "v w"}, "" +
+			"E a                                        k=\"v w\"\n" +
+			"I b                                        k=\"v w\"\n" +
+			"D c                                        k=\"v w\"\n" +
+			"  d                                        k=\"v w\"\n"},
+		{LevelStringType(42), "", nil, "a\nb\nc\nd\n"}, // an unknown type has no level column
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		l := New(LogDebug, test.typ, &buf, test.prefix, 0).With(test.fields...)
+		l.SetFormat(ConsoleFormat)
+		l.Error("a")
+		l.Info("b")
+		l.Debug("c")
+		l.Print("d")
+		if buf.String() != test.expected {
+			t.Errorf("%s: got %q; want %q", test.typ, buf.String(), test.expected)
+		}
+	}
+}
+
+func TestConsoleFormatCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", Lshortfile)
+	l.SetFormat(ConsoleFormat)
+	_, file, line, _ := runtime.Caller(0)
+	l.Info("first line\nsecond line\nthird line")
+	caller := fmt.Sprintf("%-20s", abbreviateCaller(file)+":"+fmt.Sprint(line+1))
+	indent := strings.Repeat(" ", len("INFO  ")+len(caller)+1)
+	expected := "INFO  " + caller + " first line\n" + indent + "second line\n" + indent + "third line\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+func TestConsoleFormatColor(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0)
+	l.SetFormat(ConsoleFormat)
+	l.SetColorMode(ColorAlways)
+	l.Info("a")
+	if buf.String() != "\x1b[36mINFO\x1b[0m  a\n" {
+		t.Errorf("got %q; want %q", buf.String(), "\x1b[36mINFO\x1b[0m  a\n")
+	}
+}
+
+func TestAbbreviateCaller(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"/a/b/c/d.go", "c/d.go"},
+		{"c/d.go", "c/d.go"},
+		{"/d.go", "/d.go"},
+		{"d.go", "d.go"},
+		{"???", "???"},
+	}
+	for _, test := range tests {
+		if v := abbreviateCaller(test.file); v != test.expected {
+			t.Errorf("%s: got %q; want %q", test.file, v, test.expected)
+		}
+	}
+}
+
+func TestFormatByName(t *testing.T) {
+	tests := []struct {
+		name string
+		Format
+		ok bool
+	}{
+		{"", TextFormat, true},
+		{"Text", TextFormat, true},
+		{"console", ConsoleFormat, true},
+		{"json", 0, false},
+	}
+	for _, test := range tests {
+		f, ok := FormatByName(test.name)
+		if ok != test.ok {
+			t.Errorf("%s: got %v; want %v", test.name, ok, test.ok)
+		}
+		if f != test.Format {
+			t.Errorf("%s: got %s; want %s", test.name, f, test.Format)
+		}
+		if ok && test.name != "" && !strings.EqualFold(f.String(), test.name) {
+			t.Errorf("%s: got name %s", test.name, f)
+		}
+	}
+	if Format(9).String() != "Format(9)" {
+		t.Errorf("got %q; want \"Format(9)\"", Format(9).String())
+	}
+}
+
+func TestNewFromConfigFormat(t *testing.T) {
+	l, err := NewFromConfig(&Config{Format: "console"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if l.GetFormat() != ConsoleFormat {
+		t.Errorf("got %s; want console", l.GetFormat())
+	}
+	s, prefix := l.settings()
+	s.format = TextFormat
+	changes := l.reconfigure(s, prefix, nil)
+	if fmt.Sprint(changes) != "[format console -> text]" {
+		t.Errorf("got %q; want [format console -> text]", changes)
+	}
+}
+
+func TestConsoleFormatMsgPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "[svc] ", Lmsgprefix|Llevelfirst)
+	l.SetFormat(ConsoleFormat)
+	l.Error("a\nb")
+	if buf.String() != "ERROR [svc] a\n      b\n" {
+		t.Errorf("got %q; want %q", buf.String(), "ERROR [svc] a\n      b\n")
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GELFCompression is the compression used for GELF messages sent over UDP.
+type GELFCompression int
+
+const (
+	GELFGzip GELFCompression = iota // gzip compression
+	GELFZlib                        // zlib compression
+	GELFNone                        // no compression
+)
+
+// GELF chunking limits.
+const (
+	DefaultGELFChunkSize = 1420 // fits in a typical ethernet MTU
+	gelfMaxChunks        = 128
+	gelfChunkHeaderSize  = 12
+)
+
+// GELFWriter is an output that sends each log line to a Graylog-style
+// collector as a GELF 1.1 message. The message's level is the syslog severity
+// of the line's level, see SyslogWriter, and it includes the host, the
+// timestamp, the caller's file and line as the _file and _line additional
+// fields, and the fields bound to the Logger as additional fields; their keys
+// are prefixed with an underscore. Multi-line messages have their first line
+// sent as the short_message and the whole message as the full_message.
+//
+// Over UDP, messages are compressed and, if they are larger than the chunk
+// size, chunked. Over TCP, messages are not compressed and are delimited with
+// a null byte; if a write fails, the connection is reestablished and the
+// write is retried once. This is safe for concurrent use.
+type GELFWriter struct {
+	network     string
+	raddr       string
+	compression GELFCompression
+	chunkSize   int
+	host        string
+	mu          sync.Mutex // protects conn
+	conn        net.Conn
+}
+
+// DialGELF establishes a connection to the GELF collector at raddr on the
+// named network: "udp" or "tcp", including their variants, e.g. "udp4". The
+// compression and chunkSize are only used over UDP; a chunkSize <= 0 uses
+// DefaultGELFChunkSize. TCP requires GELFNone.
+func DialGELF(network, raddr string, compression GELFCompression, chunkSize int) (*GELFWriter, error) {
+	switch {
+	case strings.HasPrefix(network, "udp"):
+	case strings.HasPrefix(network, "tcp"):
+		if compression != GELFNone {
+			return nil, errors.New("ezlog: GELF over tcp can't be compressed")
+		}
+	default:
+		return nil, errors.New("ezlog: unsupported network: " + network)
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultGELFChunkSize
+	}
+	if chunkSize <= gelfChunkHeaderSize {
+		return nil, fmt.Errorf("ezlog: GELF chunk size too small: %d", chunkSize)
+	}
+	host, _ := os.Hostname()
+	w := &GELFWriter{network: network, raddr: raddr, compression: compression, chunkSize: chunkSize, host: host}
+	c, err := net.Dial(network, raddr)
+	if err != nil {
+		return nil, err
+	}
+	w.conn = c
+	return w, nil
+}
+
+// Write sends p as a message without a level; its level is notice.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	err := w.WriteRecord(&Record{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteRecord sends r as a GELF message.
+func (w *GELFWriter) WriteRecord(r *Record) error {
+	b, err := encodeGELF(r, w.host)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(w.network, "tcp") {
+		return w.writeTCP(append(b, 0))
+	}
+	b, err = w.compress(b)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return errors.New("ezlog: GELF writer is closed")
+	}
+	if len(b) <= w.chunkSize {
+		_, err = w.conn.Write(b)
+		return err
+	}
+	return w.writeChunks(b)
+}
+
+// Close closes the connection to the collector.
+func (w *GELFWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *GELFWriter) writeTCP(b []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		_, err := w.conn.Write(b)
+		if err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	c, err := net.Dial(w.network, w.raddr)
+	if err != nil {
+		return err
+	}
+	w.conn = c
+	_, err = w.conn.Write(b)
+	return err
+}
+
+// writeChunks sends b as GELF chunks. The caller must hold the lock.
+func (w *GELFWriter) writeChunks(b []byte) error {
+	size := w.chunkSize - gelfChunkHeaderSize
+	n := (len(b) + size - 1) / size
+	if n > gelfMaxChunks {
+		return fmt.Errorf("ezlog: GELF message too large: %d chunks", n)
+	}
+	var id [8]byte
+	_, err := rand.Read(id[:])
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, 0, w.chunkSize)
+	for i := 0; i < n; i++ {
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(n))
+		end := (i + 1) * size
+		if end > len(b) {
+			end = len(b)
+		}
+		chunk = append(chunk, b[i*size:end]...)
+		_, err = w.conn.Write(chunk)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *GELFWriter) compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch w.compression {
+	case GELFGzip:
+		z := gzip.NewWriter(&buf)
+		z.Write(b)
+		err := z.Close()
+		return buf.Bytes(), err
+	case GELFZlib:
+		z := zlib.NewWriter(&buf)
+		z.Write(b)
+		err := z.Close()
+		return buf.Bytes(), err
+	}
+	return b, nil
+}
+
+// encodeGELF returns r as a JSON encoded GELF message.
+func encodeGELF(r *Record, host string) ([]byte, error) {
+	sev := sevNotice
+	if int(r.Level) < len(syslogSeverity) {
+		sev = syslogSeverity[r.Level]
+	}
+	m := make(map[string]interface{}, 8+len(r.Fields))
+	for _, f := range r.Fields {
+		k := gelfKey(f.Key)
+		if k == "" {
+			continue
+		}
+		m[k] = gelfValue(f.Value)
+	}
+	m["version"] = "1.1"
+	m["host"] = host
+	m["level"] = sev
+	m["timestamp"] = float64(r.Time.UnixNano()/int64(time.Millisecond)) / 1000
+	short := r.Message
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = short[:i]
+		m["full_message"] = r.Message
+	}
+	m["short_message"] = short
+	if r.File != "" {
+		m["_file"] = r.File
+		m["_line"] = r.Line
+	}
+	return json.Marshal(m)
+}
+
+// gelfKey returns k as a GELF additional field name: it is prefixed with an
+// underscore and any characters other than letters, digits, '_', '.', and '-'
+// are replaced with '_'. An empty string is returned for keys that GELF
+// doesn't allow, e.g. "id".
+func gelfKey(k string) string {
+	if k == "" || k == "id" {
+		return ""
+	}
+	b := make([]byte, 0, len(k)+1)
+	b = append(b, '_')
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.', c == '-':
+		default:
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// gelfValue returns v as a GELF additional field value: numbers are kept as
+// is; everything else is converted to a string.
+func gelfValue(v interface{}) interface{} {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,216 @@
+package ezlog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readGELFDatagram(t *testing.T, c net.PacketConn) []byte {
+	buf := make([]byte, 65536)
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := c.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	return append([]byte(nil), buf[:n]...)
+}
+
+func TestGELFWriterUDP(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	w, err := DialGELF("udp", c.LocalAddr().String(), GELFGzip, 0)
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	l := New(LogDebug, Full, w, "", 0).With("user", "arthur", "n", 42, "id", 1, "bad key", true)
+	l.Error("first\nsecond")
+	z, err := gzip.NewReader(bytes.NewReader(readGELFDatagram(t, c)))
+	if err != nil {
+		t.Fatalf("gzip: %s", err)
+	}
+	var m map[string]interface{}
+	err = json.NewDecoder(z).Decode(&m)
+	if err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+	tests := []struct {
+		key      string
+		expected interface{}
+	}{
+		{"version", "1.1"},
+		{"level", float64(3)},
+		{"short_message", "first"},
+		{"full_message", "first\nsecond"},
+		{"_user", "arthur"},
+		{"_n", float64(42)},
+		{"_bad_key", "true"},
+		{"_id", nil},
+	}
+	for _, test := range tests {
+		if m[test.key] != test.expected {
+			t.Errorf("%s: got %v; want %v", test.key, m[test.key], test.expected)
+		}
+	}
+	if m["host"] != w.host {
+		t.Errorf("host: got %v; want %s", m["host"], w.host)
+	}
+	if ts, ok := m["timestamp"].(float64); !ok || time.Since(time.Unix(int64(ts), 0)) > time.Minute {
+		t.Errorf("timestamp: got %v; want the current time", m["timestamp"])
+	}
+	if f, ok := m["_file"].(string); !ok || !strings.HasSuffix(f, "gelf_test.go") {
+		t.Errorf("_file: got %v; want a path ending in gelf_test.go", m["_file"])
+	}
+	if _, ok := m["_line"].(float64); !ok {
+		t.Errorf("_line: got %v; want a number", m["_line"])
+	}
+}
+
+func TestGELFWriterChunked(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	w, err := DialGELF("udp", c.LocalAddr().String(), GELFNone, 100)
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	msg := strings.Repeat("0123456789", 50)
+	err = w.WriteRecord(&Record{Time: time.Now(), Level: LogInfo, Message: msg})
+	if err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	var b []byte
+	var id []byte
+	for i := 0; ; i++ {
+		chunk := readGELFDatagram(t, c)
+		if len(chunk) > 100 {
+			t.Fatalf("chunk %d: got %d bytes; want at most 100", i, len(chunk))
+		}
+		if chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("chunk %d: got magic % x; want 1e 0f", i, chunk[:2])
+		}
+		if id == nil {
+			id = chunk[2:10]
+		} else if !bytes.Equal(id, chunk[2:10]) {
+			t.Fatalf("chunk %d: got id % x; want % x", i, chunk[2:10], id)
+		}
+		if int(chunk[10]) != i {
+			t.Fatalf("chunk %d: got sequence number %d", i, chunk[10])
+		}
+		b = append(b, chunk[12:]...)
+		if int(chunk[11]) == i+1 {
+			break
+		}
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+	if m["short_message"] != msg {
+		t.Errorf("short_message: got %v; want %s", m["short_message"], msg)
+	}
+	if m["level"] != float64(6) {
+		t.Errorf("level: got %v; want 6", m["level"])
+	}
+}
+
+func TestGELFWriterZlib(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	w, err := DialGELF("udp", c.LocalAddr().String(), GELFZlib, 0)
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	w.Write([]byte("hello\n"))
+	z, err := zlib.NewReader(bytes.NewReader(readGELFDatagram(t, c)))
+	if err != nil {
+		t.Fatalf("zlib: %s", err)
+	}
+	b, err := ioutil.ReadAll(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	json.Unmarshal(b, &m)
+	if m["short_message"] != "hello" || m["level"] != float64(5) {
+		t.Errorf("got %s; want a notice with short_message \"hello\"", b)
+	}
+}
+
+func TestGELFWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	_, err = DialGELF("tcp", ln.Addr().String(), GELFGzip, 0)
+	if err == nil {
+		t.Error("compressed tcp: expected an error; got none")
+	}
+	w, err := DialGELF("tcp", ln.Addr().String(), GELFNone, 0)
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	c, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	l := New(LogDebug, Full, w, "", 0)
+	l.Info("one")
+	l.Debug("two")
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(c)
+	for _, msg := range []string{"one", "two"} {
+		b, err := r.ReadBytes(0)
+		if err != nil {
+			t.Fatalf("read: %s", err)
+		}
+		var m map[string]interface{}
+		err = json.Unmarshal(b[:len(b)-1], &m)
+		if err != nil {
+			t.Fatalf("decode %q: %s", b, err)
+		}
+		if m["short_message"] != msg {
+			t.Errorf("short_message: got %v; want %s", m["short_message"], msg)
+		}
+	}
+}
+
+func TestGELFKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"user", "_user"},
+		{"req.id", "_req.id"},
+		{"a b/c", "_a_b_c"},
+		{"id", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if v := gelfKey(test.key); v != test.expected {
+			t.Errorf("%q: got %q; want %q", test.key, v, test.expected)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler is an http.Handler for inspecting and changing a Logger's level,
+// level string type, prefix, and flags.
+//
+// A GET request returns the Logger's current settings as JSON:
+//
+//	{"level": "ERROR", "level_string_type": "full", "prefix": "", "flags": ["date", "time"]}
+//
+// The level is the configured level: while it's elevated by SetLevelFor, it's
+// the level that will be restored.
+//
+// A PUT or POST request changes the settings. The request body is a JSON
+// object with the same fields; only the fields that are present are changed.
+// Level is parsed with LevelByName, level_string_type with
+// LevelStringTypeByName, and each of the flags with ParseFlag. If the request
+// has a "revert_after" field, a duration in the format accepted by
+// time.ParseDuration, the previous settings are restored after that duration:
+//
+//	{"level": "debug", "revert_after": "5m"}
+//
+// Only the settings that the request changed are restored, and only those that
+// still have the values the request set; a setting that was changed in the
+// meantime, e.g. by a Watcher's reload, is kept.
+//
+// A subsequent change cancels any pending revert. The response to a change
+// is the Logger's new settings, including a "revert_at" field if a revert is
+// pending. Each change is written to the Logger using Print.
+type Handler struct {
+	l      *Logger
+	mu     sync.Mutex  // protects the following fields
+	timer  *time.Timer // pending revert, if any
+	revert time.Time   // when the pending revert will happen
+}
+
+// NewHandler returns a Handler for l.
+func NewHandler(l *Logger) *Handler {
+	return &Handler{l: l}
+}
+
+// handlerSettings is the JSON representation of a Logger's settings.
+type handlerSettings struct {
+	Level           string     `json:"level"`
+	LevelStringType string     `json:"level_string_type"`
+	Prefix          string     `json:"prefix"`
+	Flags           []string   `json:"flags"`
+	RevertAt        *time.Time `json:"revert_at,omitempty"`
+}
+
+// handlerChange is the JSON representation of a change request. Nil fields
+// are not changed.
+type handlerChange struct {
+	Level           *string  `json:"level"`
+	LevelStringType *string  `json:"level_string_type"`
+	Prefix          *string  `json:"prefix"`
+	Flags           []string `json:"flags"`
+	RevertAfter     string   `json:"revert_after"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET", "HEAD":
+	case "PUT", "POST":
+		var c handlerChange
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&c)
+		if err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = h.change(c)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	b, err := json.Marshal(h.current())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// current returns the Logger's current settings.
+func (h *Handler) current() handlerSettings {
+	s, prefix := h.l.settings()
+	v := handlerSettings{Level: s.level.String(), LevelStringType: s.stringType.String(), Prefix: prefix, Flags: FlagNames(s.flag)}
+	h.mu.Lock()
+	if h.timer != nil {
+		t := h.revert
+		v.RevertAt = &t
+	}
+	h.mu.Unlock()
+	return v
+}
+
+// change validates and applies c. Nothing is changed if any of c's values are
+// invalid.
+func (h *Handler) change(c handlerChange) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	prev, prevPrefix := h.l.settings()
+	s, prefix := prev, prevPrefix
+	var ok bool
+	if c.Level != nil {
+		s.level, ok = LevelByName(*c.Level)
+		if !ok {
+			return UnknownLevelError{*c.Level}
+		}
+	}
+	if c.LevelStringType != nil {
+		s.stringType, ok = LevelStringTypeByName(*c.LevelStringType)
+		if !ok {
+			return fmt.Errorf("unknown level string type: %s", *c.LevelStringType)
+		}
+	}
+	if c.Prefix != nil {
+		prefix = *c.Prefix
+	}
+	if c.Flags != nil {
+		s.flag = 0
+		for _, v := range c.Flags {
+			f, err := ParseFlag(v)
+			if err != nil {
+				return err
+			}
+			s.flag |= f
+		}
+	}
+	var d time.Duration
+	if c.RevertAfter != "" {
+		var err error
+		d, err = time.ParseDuration(c.RevertAfter)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("revert_after must be positive")
+		}
+	}
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	changes := h.l.reconfigure(s, prefix, nil)
+	if len(changes) > 0 {
+		h.l.Printf("ezlog: changed via http: %s", strings.Join(changes, ", "))
+	}
+	if d > 0 {
+		var t *time.Timer
+		t = time.AfterFunc(d, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if h.timer != t {
+				return // superseded by a later change
+			}
+			h.timer = nil
+			// only revert what c changed and nothing has changed since,
+			// e.g. by a Watcher's reload or a SetFlags call.
+			r, rPrefix := h.l.settings()
+			if c.Level != nil && r.level == s.level {
+				r.level = prev.level
+			}
+			if c.LevelStringType != nil && r.stringType == s.stringType {
+				r.stringType = prev.stringType
+			}
+			if c.Prefix != nil && rPrefix == prefix {
+				rPrefix = prevPrefix
+			}
+			if c.Flags != nil && r.flag == s.flag {
+				r.flag = prev.flag
+			}
+			changes := h.l.reconfigure(r, rPrefix, nil)
+			if len(changes) > 0 {
+				h.l.Printf("ezlog: reverted: %s", strings.Join(changes, ", "))
+			}
+		})
+		h.timer = t
+		h.revert = time.Now().Add(d)
+	}
+	return nil
+}
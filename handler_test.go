@@ -0,0 +1,145 @@
+package ezlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerGet(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Short, &buf, "abc", Ldate|Lshortfile)
+	h := NewHandler(l)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/log", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d; want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type: got %q; want \"application/json\"", ct)
+	}
+	expected := `{"level":"INFO","level_string_type":"short","prefix":"abc","flags":["date","shortfile"]}`
+	if w.Body.String() != expected {
+		t.Errorf("got %s; want %s", w.Body.String(), expected)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/log", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status: got %d; want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandlerChange(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogError, Full, &buf, "", 0)
+	h := NewHandler(l)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/log", strings.NewReader(`{"level": "debug", "level_string_type": "char", "prefix": "x ", "flags": ["none"]}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d; want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	expected := `{"level":"DEBUG","level_string_type":"char","prefix":"x ","flags":["none"]}`
+	if w.Body.String() != expected {
+		t.Errorf("got %s; want %s", w.Body.String(), expected)
+	}
+	if l.GetLevel() != LogDebug {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	if buf.String() != "x ezlog: changed via http: level ERROR -> DEBUG, level string type full -> char, prefix \"\" -> \"x \"\n" {
+		t.Errorf("got %q; want the change to be logged", buf.String())
+	}
+
+	tests := []struct {
+		body string
+		err  string
+	}{
+		{`{"level": "verbose"}`, "unknown log level: verbose\n"},
+		{`{"level_string_type": "long"}`, "unknown level string type: long\n"},
+		{`{"level": "info", "flags": ["zdate"]}`, "unknown log flag: zdate\n"},
+		{`{"level": "info", "revert_after": "soon"}`, "time: invalid duration \"soon\"\n"},
+		{`{"level": "info", "revert_after": "-1s"}`, "revert_after must be positive\n"},
+		{`{"lvl": "info"}`, "invalid request: json: unknown field \"lvl\"\n"},
+	}
+	for _, test := range tests {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/log", strings.NewReader(test.body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status: got %d; want %d", test.body, w.Code, http.StatusBadRequest)
+		}
+		if w.Body.String() != test.err {
+			t.Errorf("%s: got %q; want %q", test.body, w.Body.String(), test.err)
+		}
+		if l.GetLevel() != LogDebug {
+			t.Errorf("%s: level: got %s; want %s", test.body, l.GetLevel(), LogDebug)
+		}
+	}
+}
+
+func TestHandlerRevert(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogError, Full, &buf, "", 0)
+	h := NewHandler(l)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/log", strings.NewReader(`{"level": "debug", "revert_after": "20ms"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d; want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var v handlerSettings
+	err := json.Unmarshal(w.Body.Bytes(), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.RevertAt == nil {
+		t.Error("revert_at: expected a value; got none")
+	}
+	if !waitForLevel(l, LogError) {
+		t.Fatalf("level: got %s; want %s", l.GetLevel(), LogError)
+	}
+
+	// a later change cancels the pending revert
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/log", strings.NewReader(`{"level": "debug", "revert_after": "20ms"}`)))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/log", strings.NewReader(`{"level": "info"}`)))
+	time.Sleep(50 * time.Millisecond)
+	if l.GetLevel() != LogInfo {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/log", nil))
+	if strings.Contains(w.Body.String(), "revert_at") {
+		t.Errorf("got %s; want no revert_at", w.Body.String())
+	}
+}
+
+func TestHandlerRevertKeepsLaterChanges(t *testing.T) {
+	var buf syncBuffer
+	l := New(LogError, Full, &buf, "", 0)
+	h := NewHandler(l)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("PUT", "/log", strings.NewReader(`{"level": "debug", "prefix": "x ", "flags": ["date"], "revert_after": "20ms"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d; want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	// changed during the revert window, e.g. by a reload or in code
+	l.SetLevel(LogInfo)
+	l.SetFlags(Ltime)
+	for i := 0; i < 200 && l.Prefix() != ""; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if l.Prefix() != "" {
+		t.Fatalf("prefix: got %q; want it reverted", l.Prefix())
+	}
+	if l.GetLevel() != LogInfo {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+	if l.Flags() != Ltime {
+		t.Errorf("flags: got %d; want %d", l.Flags(), Ltime)
+	}
+	if !strings.Contains(buf.String(), "ezlog: reverted: prefix \"x \" -> \"\"\n") {
+		t.Errorf("got %q; want only the prefix reverted", buf.String())
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import "fmt"
+
+// LogFatal and LogPanic are the levels of the lines written by the Fatal and
+// Panic methods, e.g. for a Hook's Levels. They aren't levels that a Logger
+// can be set to.
+const (
+	LogFatal = logFatal
+	LogPanic = logPanic
+)
+
+// A Hook is called with the Record of each log line of its Levels that the
+// logger writes, e.g. to count Error lines or to alert on Fatal lines.
+type Hook interface {
+	// Levels returns the levels of the lines the Hook is fired for. Lines
+	// written by Print have a level of 0.
+	Levels() []Level
+	// Fire is called with the line's Record before it's written to the
+	// logger's outputs. The Record must not be retained after Fire returns
+	// and its Fields must not be modified; they may be reused. Fire is
+	// called while the logger is writing the line: it must not log to the
+	// logger.
+	Fire(r *Record) error
+}
+
+// AddHook adds a Hook that is fired for the lines of its levels that pass the
+// logger's level, sampling, and rate limiting. Hooks are fired in the order
+// they were added. A Hook that returns an error or panics doesn't keep the line
+// from being written; the error, or the recovered panic, is reported in an
+// error line written after it.
+//
+// Hooks aren't fired for the lines the logger writes itself: the summaries
+// of the lines collapsed by SetDedup or discarded by SetRateLimit, and the
+// reports of hook errors.
+func (l *Logger) AddHook(h Hook) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// fireHooks fires the hooks of r's level and returns their errors, including
+// those of the hooks that panicked. The caller must hold outMu.
+func (l *Logger) fireHooks(r *Record) []error {
+	var errs []error
+	for _, h := range l.hooks {
+		if !hookLevel(h, r.Level) {
+			continue
+		}
+		if err := fireHook(h, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// hookLevel reports whether h is fired for lines of level lvl.
+func hookLevel(h Hook, lvl Level) bool {
+	for _, v := range h.Levels() {
+		if v == lvl {
+			return true
+		}
+	}
+	return false
+}
+
+// fireHook fires h, recovering any panic as an error.
+func fireHook(h Hook, r *Record) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return h.Fire(r)
+}
+
+// reportHookErrors writes an error line, with the caller of r's line, for
+// each of errs; the hooks aren't fired for these lines. The caller must hold
+// outMu.
+func (l *Logger) reportHookErrors(r *Record, errs []error) {
+	l.internal = true
+	defer func() { l.internal = false }()
+	for _, err := range errs {
+		l.formatLine(r.Time, LogError, r.File, r.Line, []byte("ezlog: hook: "+err.Error()))
+	}
+}
+
+// AddHook adds a Hook that is fired for the lines of its levels that the
+// standard logger writes.
+func AddHook(h Hook) {
+	std.AddHook(h)
+}
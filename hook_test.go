@@ -0,0 +1,106 @@
+package ezlog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHook keeps copies of the Records it's fired with.
+type testHook struct {
+	levels  []Level
+	records []Record
+	err     error
+	panic   bool
+}
+
+func (h *testHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *testHook) Fire(r *Record) error {
+	if h.panic {
+		panic("boom")
+	}
+	c := *r
+	c.Fields = append([]Field(nil), r.Fields...)
+	h.records = append(h.records, c)
+	return h.err
+}
+
+func TestAddHook(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", Lshortfile).With("k", "v")
+	errs := &testHook{levels: []Level{LogError}}
+	panics := &testHook{levels: []Level{LogPanic, 0}}
+	l.AddHook(errs)
+	l.AddHook(panics)
+	l.Error("a")
+	l.Info("b")
+	l.Debug("c") // discarded: hooks aren't fired
+	l.Print("d")
+	func() {
+		defer func() { recover() }()
+		l.Panic("e")
+	}()
+	if len(errs.records) != 1 || errs.records[0].Message != "a" || errs.records[0].Level != LogError {
+		t.Errorf("got %v; want a record of the error line", errs.records)
+	}
+	r := errs.records[0]
+	if r.File == "" || r.Line == 0 || len(r.Fields) != 1 || r.Fields[0].Key != "k" {
+		t.Errorf("got %v; want a record with the caller and fields", r)
+	}
+	if len(panics.records) != 2 || panics.records[0].Message != "d" || panics.records[1].Message != "e" {
+		t.Errorf("got %v; want records of the print and panic lines", panics.records)
+	}
+	if buf.Len() == 0 {
+		t.Error("got no lines; want the lines written")
+	}
+}
+
+func TestHookErrors(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0)
+	l.AddHook(&testHook{levels: []Level{LogError, LogInfo}, panic: true})
+	l.AddHook(&testHook{levels: []Level{LogInfo}, err: errors.New("no alerting service")})
+	l.Info("a")
+	l.Error("b")
+	expected := "" +
+		"INFO: a\n" +
+		"ERROR: ezlog: hook: panic: boom\n" +
+		"ERROR: ezlog: hook: no alerting service\n" +
+		"ERROR: b\n" +
+		"ERROR: ezlog: hook: panic: boom\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+func TestHookSummaries(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0)
+	h := &testHook{levels: []Level{LogError}}
+	l.AddHook(h)
+	l.SetDedup(time.Hour)
+	for i := 0; i < 4; i++ {
+		l.Error("a")
+	}
+	l.Error("b")
+	l.SetDedup(0)
+	l.SetRateLimit(RateLimitTemplate, 1, time.Hour, 1)
+	l.Error("c")
+	l.Error("c")
+	l.Close()
+	if !strings.Contains(buf.String(), "repeated 3 times") || !strings.Contains(buf.String(), "suppressed 1 similar messages") {
+		t.Fatalf("got %q; want the dedup and rate limit summaries", buf.String())
+	}
+	var msgs []string
+	for _, r := range h.records {
+		msgs = append(msgs, r.Message)
+	}
+	if strings.Join(msgs, ",") != "a,b,c" {
+		t.Errorf("got %q; want the hook fired only for the lines that were logged", msgs)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build linux
+// +build linux
+
+package ezlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// JournaldSocket is the path of systemd-journald's native protocol socket.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldWriter is an output that sends each log line to systemd-journald
+// using its native protocol. Each entry has the following fields:
+//
+//	MESSAGE:            the message
+//	PRIORITY:           the syslog severity of the line's level; see SyslogWriter
+//	SYSLOG_IDENTIFIER:  the identifier, if it isn't empty
+//	CODE_FILE:          the caller's file
+//	CODE_LINE:          the caller's line
+//
+// followed by the fields bound to the Logger. The keys of bound fields are
+// upper-cased, characters other than A-Z, 0-9, and '_' are replaced with '_',
+// and leading underscores and digits are removed, as journald requires.
+//
+// Entries that are too large to be sent as a single datagram are written to
+// an unlinked temporary file whose descriptor is passed to journald instead.
+// This is safe for concurrent use.
+type JournaldWriter struct {
+	identifier string
+	addr       *net.UnixAddr
+	mu         sync.Mutex // protects conn
+	conn       *net.UnixConn
+}
+
+// DialJournald returns a JournaldWriter that sends entries to the journald
+// socket at path; if path is empty, JournaldSocket is used. An error is
+// returned if path isn't a socket. The identifier is sent as each entry's
+// SYSLOG_IDENTIFIER.
+func DialJournald(path, identifier string) (*JournaldWriter, error) {
+	if path == "" {
+		path = JournaldSocket
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return nil, errors.New("ezlog: not a socket: " + path)
+	}
+	// The socket isn't connected so that file descriptors can be sent to
+	// journald with WriteMsgUnix.
+	c, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournaldWriter{identifier: identifier, addr: &net.UnixAddr{Name: path, Net: "unixgram"}, conn: c}, nil
+}
+
+// Write sends p as an entry without a level; its priority is notice.
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	err := w.WriteRecord(&Record{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteRecord sends r as a journal entry.
+func (w *JournaldWriter) WriteRecord(r *Record) error {
+	sev := sevNotice
+	if int(r.Level) < len(syslogSeverity) {
+		sev = syslogSeverity[r.Level]
+	}
+	b := appendJournalField(nil, "MESSAGE", r.Message)
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(sev))
+	if w.identifier != "" {
+		b = appendJournalField(b, "SYSLOG_IDENTIFIER", w.identifier)
+	}
+	if r.File != "" {
+		b = appendJournalField(b, "CODE_FILE", r.File)
+		b = appendJournalField(b, "CODE_LINE", strconv.Itoa(r.Line))
+	}
+	for _, f := range r.Fields {
+		k := journalKey(f.Key)
+		if k == "" {
+			continue
+		}
+		b = appendJournalField(b, k, fmt.Sprint(f.Value))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return errors.New("ezlog: journald writer is closed")
+	}
+	_, err := w.conn.WriteToUnix(b, w.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+	return w.sendFile(b)
+}
+
+// Close closes the connection to journald.
+func (w *JournaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// sendFile writes b to an unlinked temporary file and passes its descriptor
+// to journald. The caller must hold the lock.
+func (w *JournaldWriter) sendFile(b []byte) error {
+	f, err := ioutil.TempFile("/dev/shm", "ezlog-journal-")
+	if err != nil {
+		f, err = ioutil.TempFile("", "ezlog-journal-")
+		if err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+	err = os.Remove(f.Name())
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err != nil {
+		return err
+	}
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), w.addr)
+	return err
+}
+
+// appendJournalField appends the field k=v to b. Values that contain a
+// newline use the binary encoding: the key, a newline, the value's length as
+// a little endian uint64, and the value.
+func appendJournalField(b []byte, k, v string) []byte {
+	b = append(b, k...)
+	if strings.IndexByte(v, '\n') < 0 {
+		b = append(b, '=')
+		b = append(b, v...)
+		return append(b, '\n')
+	}
+	var n [8]byte
+	binary.LittleEndian.PutUint64(n[:], uint64(len(v)))
+	b = append(b, '\n')
+	b = append(b, n[:]...)
+	b = append(b, v...)
+	return append(b, '\n')
+}
+
+// journalKey returns k as a valid journal field name. An empty string is
+// returned if nothing valid remains.
+func journalKey(k string) string {
+	b := make([]byte, 0, len(k))
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			c = '_'
+		}
+		// field names can't start with an underscore or a digit
+		if len(b) == 0 && (c == '_' || c >= '0' && c <= '9') {
+			continue
+		}
+		b = append(b, c)
+	}
+	if len(b) > 64 {
+		b = b[:64]
+	}
+	return string(b)
+}
@@ -0,0 +1,179 @@
+//go:build linux
+// +build linux
+
+package ezlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// parseJournalEntry parses a native protocol journal entry.
+func parseJournalEntry(t *testing.T, b []byte) map[string]string {
+	m := map[string]string{}
+	for len(b) > 0 {
+		i := bytes.IndexAny(b, "=\n")
+		if i < 0 {
+			t.Fatalf("malformed entry: %q", b)
+		}
+		k := string(b[:i])
+		if b[i] == '=' {
+			b = b[i+1:]
+			j := bytes.IndexByte(b, '\n')
+			m[k] = string(b[:j])
+			b = b[j+1:]
+			continue
+		}
+		b = b[i+1:]
+		n := binary.LittleEndian.Uint64(b[:8])
+		m[k] = string(b[8 : 8+n])
+		b = b[8+n+1:]
+	}
+	return m
+}
+
+func journaldListener(t *testing.T) (*net.UnixConn, string, func()) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "journal.sock")
+	c, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return c, path, func() {
+		c.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestJournaldWriter(t *testing.T) {
+	c, path, cleanup := journaldListener(t)
+	defer cleanup()
+	w, err := DialJournald(path, "ezlog-test")
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	l := New(LogDebug, Full, w, "", 0).With("user-id", 42, "_trusted", "x", "req id", "a\nb")
+	_, _, line, _ := runtime.Caller(0)
+	l.Error("first line\nsecond line")
+	buf := make([]byte, 4096)
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := c.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	m := parseJournalEntry(t, buf[:n])
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"MESSAGE", "first line\nsecond line"},
+		{"PRIORITY", "3"},
+		{"SYSLOG_IDENTIFIER", "ezlog-test"},
+		{"CODE_LINE", strconv.Itoa(line + 1)},
+		{"USER_ID", "42"},
+		{"TRUSTED", "x"},
+		{"REQ_ID", "a\nb"},
+	}
+	for _, test := range tests {
+		if m[test.key] != test.expected {
+			t.Errorf("%s: got %q; want %q", test.key, m[test.key], test.expected)
+		}
+	}
+	if !strings.HasSuffix(m["CODE_FILE"], "journald_test.go") {
+		t.Errorf("CODE_FILE: got %q; want a path ending in journald_test.go", m["CODE_FILE"])
+	}
+}
+
+func TestJournaldWriterLarge(t *testing.T) {
+	c, path, cleanup := journaldListener(t)
+	defer cleanup()
+	w, err := DialJournald(path, "")
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	msg := strings.Repeat("x", 4<<20)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- w.WriteRecord(&Record{Level: LogInfo, Message: msg})
+	}()
+	oob := make([]byte, syscall.CmsgSpace(4))
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, oobn, _, _, err := c.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected a control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected a file descriptor: %v", err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+	f.Seek(0, 0)
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := parseJournalEntry(t, b)
+	if m["MESSAGE"] != msg {
+		t.Errorf("MESSAGE: got %d bytes; want %d", len(m["MESSAGE"]), len(msg))
+	}
+	if m["PRIORITY"] != "6" {
+		t.Errorf("PRIORITY: got %q; want \"6\"", m["PRIORITY"])
+	}
+}
+
+func TestJournalKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"user", "USER"},
+		{"User_ID", "USER_ID"},
+		{"req-id", "REQ_ID"},
+		{"__x", "X"},
+		{"9lives", "LIVES"},
+		{"_", ""},
+		{strings.Repeat("a", 70), strings.Repeat("A", 64)},
+	}
+	for _, test := range tests {
+		if v := journalKey(test.key); v != test.expected {
+			t.Errorf("%q: got %q; want %q", test.key, v, test.expected)
+		}
+	}
+}
+
+func TestDialJournaldNotSocket(t *testing.T) {
+	f, err := ioutil.TempFile("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	_, err = DialJournald(f.Name(), "")
+	if err == nil {
+		t.Error("expected an error; got none")
+	}
+}
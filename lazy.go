@@ -0,0 +1,96 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import "sync/atomic"
+
+// Enabled reports whether lines of level lvl are written by the logger; lvl
+// is one of LogError, LogInfo, or LogDebug. It can be used to skip work that
+// is only needed for log lines:
+//
+//	if l.Enabled(ezlog.LogDebug) {
+//		l.Debugf("state: %s", dump(state))
+//	}
+func (l *Logger) Enabled(lvl Level) bool {
+	return lvl > LogNone && Level(atomic.LoadInt32(&l.level)) >= lvl
+}
+
+// Errorfn writes an error line to the logger with the message returned by f.
+// If the level is less than LogError, the line will be discarded without f
+// being called.
+func (l *Logger) Errorfn(f func() string) {
+	if atomic.LoadInt32(&l.level) < int32(LogError) {
+		return
+	}
+	l.printfn(LogError, f)
+}
+
+// Infofn writes an info line to the logger with the message returned by f. If
+// the level is less than LogInfo, the line will be discarded without f being
+// called.
+func (l *Logger) Infofn(f func() string) {
+	if atomic.LoadInt32(&l.level) < int32(LogInfo) {
+		return
+	}
+	l.printfn(LogInfo, f)
+}
+
+// Debugfn writes a debug line to the logger with the message returned by f.
+// If the level is less than LogDebug, the line will be discarded without f
+// being called.
+func (l *Logger) Debugfn(f func() string) {
+	if atomic.LoadInt32(&l.level) < int32(LogDebug) {
+		return
+	}
+	l.printfn(LogDebug, f)
+}
+
+// printfn writes a log line of level lvl with the message returned by f.
+func (l *Logger) printfn(lvl Level, f func() string) {
+	if l.sampledOut(lvl) {
+		return
+	}
+	rl := l.rateLimiter()
+	if rl != nil && rl.key == RateLimitCaller && rl.limited(l, lvl, l.callDepth+1, "") {
+		return
+	}
+	msg := f()
+	if rl == nil || rl.key == RateLimitCaller || !rl.limited(l, lvl, l.callDepth+1, msg) {
+		l.output(lvl, l.callDepth+1, []byte(msg))
+	}
+}
+
+// Enabled reports whether lines of level lvl are written by the standard
+// logger.
+func Enabled(lvl Level) bool {
+	return std.Enabled(lvl)
+}
+
+// Errorfn writes an error line to the standard logger with the message
+// returned by f, which is only called if the line will be written.
+func Errorfn(f func() string) {
+	std.Errorfn(f)
+}
+
+// Infofn writes an info line to the standard logger with the message returned
+// by f, which is only called if the line will be written.
+func Infofn(f func() string) {
+	std.Infofn(f)
+}
+
+// Debugfn writes a debug line to the standard logger with the message
+// returned by f, which is only called if the line will be written.
+func Debugfn(f func() string) {
+	std.Debugfn(f)
+}
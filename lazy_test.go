@@ -0,0 +1,94 @@
+package ezlog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected [3]bool // LogError, LogInfo, LogDebug
+	}{
+		{LogNone, [3]bool{false, false, false}},
+		{LogError, [3]bool{true, false, false}},
+		{LogInfo, [3]bool{true, true, false}},
+		{LogDebug, [3]bool{true, true, true}},
+	}
+	for _, test := range tests {
+		l := New(test.level, Full, nil, "", 0)
+		for i, lvl := range []Level{LogError, LogInfo, LogDebug} {
+			if v := l.Enabled(lvl); v != test.expected[i] {
+				t.Errorf("%s: %s: got %v; want %v", test.level, lvl, v, test.expected[i])
+			}
+		}
+		if l.Enabled(LogNone) {
+			t.Errorf("%s: none: got true; want false", test.level)
+		}
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", Lshortfile)
+	calls := 0
+	f := func(s string) func() string {
+		return func() string {
+			calls++
+			return s
+		}
+	}
+	_, file, line, _ := runtime.Caller(0)
+	l.Errorfn(f("error"))
+	l.Infofn(f("info"))
+	l.Debugfn(f("debug"))
+	file = shortFile(file)
+	expected := fmt.Sprintf("%s:%d: ERROR: error\n%s:%d: INFO: info\n", file, line+1, file, line+2)
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	if calls != 2 {
+		t.Errorf("calls: got %d; want 2", calls)
+	}
+	l.SetLevel(LogNone)
+	l.Errorfn(f("error"))
+	if calls != 2 {
+		t.Errorf("calls: got %d; want 2", calls)
+	}
+}
+
+func TestLazyStd(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFlags(Lshortfile)
+	SetLevel(LogDebug)
+	prefix := Prefix()
+	SetPrefix("")
+	defer func() {
+		SetOutput(os.Stderr)
+		SetFlags(LstdFlags)
+		SetLevel(LogError)
+		SetPrefix(prefix)
+	}()
+	_, file, line, _ := runtime.Caller(0)
+	Debugfn(func() string { return "debug" })
+	expected := fmt.Sprintf("%s:%d: DEBUG: debug\n", shortFile(file), line+1)
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	if !Enabled(LogDebug) {
+		t.Error("debug: got false; want true")
+	}
+}
+
+func shortFile(file string) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			return file[i+1:]
+		}
+	}
+	return file
+}
@@ -0,0 +1,256 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NetWriter defaults.
+const (
+	DefaultMinBackoff   = 100 * time.Millisecond
+	DefaultMaxBackoff   = 30 * time.Second
+	DefaultFlushTimeout = 5 * time.Second
+	netWriteTimeout     = 10 * time.Second
+)
+
+// NetWriter is an output that sends log lines to a collector over a tcp or
+// unix stream connection. Writes don't block on the network: lines are
+// buffered and sent by a separate goroutine. While disconnected, up to
+// maxBuffered lines are kept; once the buffer is full, the oldest line is
+// dropped for each new one. A lost connection is reestablished with
+// exponential backoff.
+//
+// Close flushes the buffered lines, waiting at most the flush timeout. To have
+// a Logger's Close, Fatal, and Panic flush the lines, add the NetWriter's
+// Close to the Logger with AddFunc:
+//
+//	w, err := ezlog.NewNetWriter("tcp", "collector:5170", 1000)
+//	...
+//	l := ezlog.New(ezlog.LogInfo, ezlog.Full, w, "", ezlog.LstdFlags)
+//	l.AddFunc(w.Close)
+//
+// This is safe for concurrent use.
+type NetWriter struct {
+	network     string
+	addr        string
+	maxBuffered int
+	wg          sync.WaitGroup
+	done        chan struct{} // closed by Close
+	ctx         context.Context
+	cancel      context.CancelFunc // ends a dial in progress at the flush deadline
+	mu          sync.Mutex         // protects the following fields
+	cond        *sync.Cond
+	queue       [][]byte
+	dropped     int
+	closed      bool
+	deadline    time.Time // when Close stops trying to flush
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	flushTime   time.Duration
+	conn        net.Conn // only set by the sending goroutine
+}
+
+// NewNetWriter returns a NetWriter that sends lines to addr on the named
+// network: "tcp", "tcp4", "tcp6", or "unix". The connection is made by the
+// sending goroutine; an unreachable addr is not an error. A maxBuffered < 1
+// is treated as 1.
+func NewNetWriter(network, addr string, maxBuffered int) (*NetWriter, error) {
+	if !strings.HasPrefix(network, "tcp") && network != "unix" {
+		return nil, errors.New("ezlog: unsupported network: " + network)
+	}
+	if maxBuffered < 1 {
+		maxBuffered = 1
+	}
+	w := &NetWriter{
+		network:     network,
+		addr:        addr,
+		maxBuffered: maxBuffered,
+		done:        make(chan struct{}),
+		minBackoff:  DefaultMinBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		flushTime:   DefaultFlushTimeout,
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.cond = sync.NewCond(&w.mu)
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+// SetBackoff sets the minimum and maximum delays between reconnection
+// attempts. The delay starts at min and doubles after each failed attempt, up
+// to max.
+func (w *NetWriter) SetBackoff(min, max time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.minBackoff = min
+	w.maxBackoff = max
+}
+
+// SetFlushTimeout sets how long Close tries to send the buffered lines.
+func (w *NetWriter) SetFlushTimeout(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushTime = d
+}
+
+// Dropped returns the number of lines that were dropped because the buffer
+// was full.
+func (w *NetWriter) Dropped() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// Write buffers a copy of p to be sent. An error is only returned if w is
+// closed.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	b := append([]byte(nil), p...)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("ezlog: write to closed NetWriter")
+	}
+	if len(w.queue) >= w.maxBuffered {
+		w.queue = w.queue[1:]
+		w.dropped++
+	}
+	w.queue = append(w.queue, b)
+	w.cond.Signal()
+	return len(p), nil
+}
+
+// Close sends any buffered lines, waiting at most the flush timeout, and then
+// closes the connection. An error is returned if any lines couldn't be sent.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.deadline = time.Now().Add(w.flushTime)
+	// a dial or write in progress must not outlast the flush deadline either.
+	if w.conn != nil {
+		w.conn.SetWriteDeadline(w.deadline)
+	}
+	stop := time.AfterFunc(w.flushTime, w.cancel)
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	close(w.done)
+	w.wg.Wait()
+	stop.Stop()
+	w.cancel()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.queue) > 0 {
+		return fmt.Errorf("ezlog: %d buffered lines not sent to %s", len(w.queue), w.addr)
+	}
+	return nil
+}
+
+// run sends the buffered lines, reconnecting as needed, until w is closed and
+// either all lines have been sent or the flush deadline has passed.
+func (w *NetWriter) run() {
+	defer w.wg.Done()
+	var backoff time.Duration
+	for {
+		w.mu.Lock()
+		for len(w.queue) == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.closed && (len(w.queue) == 0 || time.Now().After(w.deadline)) {
+			w.mu.Unlock()
+			if w.conn != nil {
+				w.conn.Close()
+			}
+			return
+		}
+		minBackoff, maxBackoff, deadline := w.minBackoff, w.maxBackoff, w.deadline
+		w.mu.Unlock()
+
+		if w.conn == nil {
+			w.mu.Lock()
+			d := net.Dialer{Deadline: w.ioDeadline()}
+			w.mu.Unlock()
+			c, err := d.DialContext(w.ctx, w.network, w.addr)
+			if err != nil {
+				if backoff < minBackoff {
+					backoff = minBackoff
+				} else if backoff *= 2; backoff > maxBackoff {
+					backoff = maxBackoff
+				}
+				w.sleep(backoff, deadline)
+				continue
+			}
+			w.mu.Lock()
+			w.conn = c
+			w.mu.Unlock()
+			backoff = 0
+		}
+
+		w.mu.Lock()
+		p := w.queue[0]
+		w.queue = w.queue[1:]
+		w.conn.SetWriteDeadline(w.ioDeadline())
+		w.mu.Unlock()
+		_, err := w.conn.Write(p)
+		if err == nil {
+			continue
+		}
+		w.conn.Close()
+		// put the line back unless newer lines have filled the buffer
+		w.mu.Lock()
+		w.conn = nil
+		if len(w.queue) < w.maxBuffered {
+			w.queue = append([][]byte{p}, w.queue...)
+		} else {
+			w.dropped++
+		}
+		w.mu.Unlock()
+	}
+}
+
+// ioDeadline returns the deadline for a dial or write: netWriteTimeout from
+// now, but, once w is closed, no later than the flush deadline. The caller
+// must hold mu.
+func (w *NetWriter) ioDeadline() time.Time {
+	d := time.Now().Add(netWriteTimeout)
+	if w.closed && w.deadline.Before(d) {
+		return w.deadline
+	}
+	return d
+}
+
+// sleep sleeps for d. Before w is closed, the sleep ends early when w is
+// closed; after, it ends at the flush deadline.
+func (w *NetWriter) sleep(d time.Duration, deadline time.Time) {
+	select {
+	case <-w.done:
+		if until := time.Until(deadline); until < d {
+			d = until
+		}
+		if d > 0 {
+			time.Sleep(d)
+		}
+	case <-time.After(d):
+	}
+}
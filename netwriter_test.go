@@ -0,0 +1,170 @@
+package ezlog
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readLines reads n lines from the first connection accepted by ln.
+func readLines(t *testing.T, ln net.Listener, n int) []string {
+	c, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("accept: %s", err)
+	}
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r := bufio.NewReader(c)
+	var lines []string
+	for i := 0; i < n; i++ {
+		s, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read line %d: %s", i, err)
+		}
+		lines = append(lines, s)
+	}
+	return lines
+}
+
+func TestNetWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	w, err := NewNetWriter("tcp", ln.Addr().String(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	l := New(LogInfo, Full, w, "", 0)
+	l.AddFunc(w.Close)
+	l.Error("one")
+	l.Info("two")
+	l.Debug("three")
+	lines := readLines(t, ln, 2)
+	if lines[0] != "ERROR: one\n" || lines[1] != "INFO: two\n" {
+		t.Errorf("got %q; want [\"ERROR: one\\n\" \"INFO: two\\n\"]", lines)
+	}
+	l.Close()
+	_, err = w.Write([]byte("closed\n"))
+	if err == nil {
+		t.Error("write after close: expected an error; got none")
+	}
+}
+
+func TestNetWriterReconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "collector.sock")
+	w, err := NewNetWriter("unix", addr, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w.SetBackoff(time.Millisecond, 10*time.Millisecond)
+	// the collector isn't up yet: the oldest line is dropped
+	w.Write([]byte("one\n"))
+	w.Write([]byte("two\n"))
+	w.Write([]byte("three\n"))
+	if w.Dropped() != 1 {
+		t.Errorf("dropped: got %d; want 1", w.Dropped())
+	}
+	ln, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := readLines(t, ln, 2)
+	if lines[0] != "two\n" || lines[1] != "three\n" {
+		t.Errorf("got %q; want [\"two\\n\" \"three\\n\"]", lines)
+	}
+	ln.Close()
+
+	// the collector restarts: lines written while it's down are sent once
+	// the connection is reestablished
+	w.Write([]byte("four\n"))
+	w.Write([]byte("five\n"))
+	time.Sleep(20 * time.Millisecond)
+	os.Remove(addr)
+	ln, err = net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan error)
+	go func() {
+		done <- w.Close()
+	}()
+	lines = readLines(t, ln, 2)
+	if lines[0] != "four\n" || lines[1] != "five\n" {
+		t.Errorf("got %q; want [\"four\\n\" \"five\\n\"]", lines)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("close: unexpected error: %s", err)
+	}
+}
+
+func TestNetWriterCloseTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	w, err := NewNetWriter("unix", filepath.Join(dir, "none.sock"), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w.SetBackoff(time.Millisecond, 5*time.Millisecond)
+	w.SetFlushTimeout(20 * time.Millisecond)
+	w.Write([]byte("lost\n"))
+	err = w.Close()
+	if err == nil {
+		t.Error("close: expected an error; got none")
+	}
+}
+
+func TestNetWriterCloseBlockedWrite(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	defer ln.Close()
+	// the collector accepts the connection but never reads from it.
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			defer c.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	w, err := NewNetWriter("tcp", ln.Addr().String(), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	w.SetFlushTimeout(100 * time.Millisecond)
+	line := make([]byte, 1<<20)
+	for i := 0; i < 64; i++ {
+		w.Write(line)
+	}
+	time.Sleep(100 * time.Millisecond) // let the sending goroutine block on a write
+	start := time.Now()
+	err = w.Close()
+	if err == nil {
+		t.Error("close: expected an error; got none")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("close took %s; want it to return at the flush timeout", d)
+	}
+}
+
+func TestNewNetWriterUnsupportedNetwork(t *testing.T) {
+	_, err := NewNetWriter("udp", "127.0.0.1:514", 10)
+	if err == nil {
+		t.Error("expected an error; got none")
+	}
+}
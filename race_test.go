@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package ezlog
+
+// raceEnabled is set when the race detector is on; it makes sync.Pool drop
+// items at random, so pooled buffers are reallocated.
+const raceEnabled = true
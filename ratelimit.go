@@ -0,0 +1,234 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitKey is what lines are grouped by when they are rate limited.
+type RateLimitKey int
+
+const (
+	// RateLimitCaller groups lines by their call site, the file and line
+	// number of the log call.
+	RateLimitCaller RateLimitKey = iota
+	// RateLimitTemplate groups lines by their format string. Lines written
+	// with the methods that don't take a format, e.g. Error or Infoln, are
+	// grouped by their message.
+	RateLimitTemplate
+)
+
+// SetRateLimit limits the number of lines, of each group of lines that share
+// a key, that the logger writes to n per interval, with bursts of up to burst
+// lines; a burst less than n is treated as n. Lines over the limit are
+// discarded. When lines were discarded, a "suppressed K similar messages"
+// line, with the level and fields of the discarded lines, is written once the
+// interval after the first discarded line has passed, or when the logger is
+// closed.
+//
+// Fatal and panic lines are never rate limited. An n or interval of 0 or less
+// removes the rate limit; any pending summary lines are written first.
+func (l *Logger) SetRateLimit(key RateLimitKey, n int, interval time.Duration, burst int) {
+	var rl *rateLimiter
+	if n > 0 && interval > 0 {
+		if burst < n {
+			burst = n
+		}
+		rl = &rateLimiter{
+			key:      key,
+			rate:     float64(n) / interval.Seconds(),
+			burst:    float64(burst),
+			interval: interval,
+			now:      time.Now,
+			buckets:  make(map[rateKey]*bucket),
+			sweepAt:  minSweepAt,
+		}
+	}
+	old := l.rateLimiter()
+	l.limiter.Store(rl)
+	if old != nil {
+		old.flushAll(l.logger)
+	}
+}
+
+// rateLimiter returns the logger's rateLimiter; nil if lines aren't rate
+// limited.
+func (l *Logger) rateLimiter() *rateLimiter {
+	rl, _ := l.limiter.Load().(*rateLimiter)
+	return rl
+}
+
+// rateLimiter is a token bucket for each group of lines.
+type rateLimiter struct {
+	key      RateLimitKey
+	rate     float64 // tokens per second
+	burst    float64
+	interval time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	buckets  map[rateKey]*bucket
+	sweepAt  int // the number of buckets at which idle buckets are removed
+}
+
+// minSweepAt is the least number of buckets at which idle buckets are removed.
+const minSweepAt = 1024
+
+// rateKey identifies a group of lines: a file and line number for
+// RateLimitCaller or a template for RateLimitTemplate.
+type rateKey struct {
+	s    string
+	line int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// what the summary line needs; only set while lines are being suppressed.
+	suppressed int
+	lvl        Level
+	file       string
+	line       int
+	fields     []Field
+	timer      *time.Timer
+}
+
+// limited reports whether a line of level lvl is over the rate limit and
+// should be discarded. The calldepth is that of the log call, as for output;
+// template is the line's format string, or its message, and is only used for
+// RateLimitTemplate.
+func (rl *rateLimiter) limited(l *Logger, lvl Level, calldepth int, template string) bool {
+	if lvl >= logFatal {
+		return false
+	}
+	var k rateKey
+	if rl.key == RateLimitCaller {
+		_, file, line, ok := runtime.Caller(calldepth)
+		if !ok {
+			return false
+		}
+		k = rateKey{s: file, line: line}
+	} else {
+		k = rateKey{s: template}
+	}
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	b, ok := rl.buckets[k]
+	if !ok {
+		if len(rl.buckets) >= rl.sweepAt {
+			rl.sweep(now)
+		}
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[k] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * rl.rate
+		if b.tokens > rl.burst {
+			b.tokens = rl.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return false
+	}
+	if b.suppressed == 0 {
+		b.lvl = lvl
+		b.fields = l.fields
+		if rl.key == RateLimitCaller {
+			b.file, b.line = k.s, k.line
+		} else {
+			_, b.file, b.line, _ = runtime.Caller(calldepth)
+		}
+		b.timer = time.AfterFunc(rl.interval, func() { rl.flush(l.logger, k) })
+	}
+	b.suppressed++
+	return true
+}
+
+// sweep removes the buckets that are idle: full again and without a pending
+// summary. Such a bucket is the same as a new one, so removing it doesn't
+// change which lines are limited; it keeps keys that are seen once, e.g. the
+// messages of RateLimitTemplate lines that contain IDs, from growing the map
+// without bound. The caller must hold mu.
+func (rl *rateLimiter) sweep(now time.Time) {
+	for k, b := range rl.buckets {
+		if b.suppressed == 0 && b.tokens+now.Sub(b.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, k)
+		}
+	}
+	rl.sweepAt = 2 * len(rl.buckets)
+	if rl.sweepAt < minSweepAt {
+		rl.sweepAt = minSweepAt
+	}
+}
+
+// flush writes the summary line for the lines of k that were suppressed, if
+// any.
+func (rl *rateLimiter) flush(core *logger, k rateKey) {
+	rl.mu.Lock()
+	b, ok := rl.buckets[k]
+	if !ok { // flushed and swept before a stopped timer's func ran
+		rl.mu.Unlock()
+		return
+	}
+	n, lvl, file, line, fields := b.suppressed, b.lvl, b.file, b.line, b.fields
+	b.suppressed, b.fields, b.timer = 0, nil, nil
+	rl.mu.Unlock()
+	if n == 0 {
+		return
+	}
+	var what string
+	if rl.key == RateLimitCaller {
+		short := file
+		for i := len(file) - 1; i > 0; i-- {
+			if file[i] == '/' {
+				short = file[i+1:]
+				break
+			}
+		}
+		what = short + ":" + strconv.Itoa(line)
+	} else {
+		what = strconv.Quote(k.s)
+	}
+	l := &Logger{logger: core, fields: fields}
+	l.outputAt(lvl, file, line, []byte(fmt.Sprintf("suppressed %d similar messages (%s)", n, what)))
+}
+
+// flushAll stops the pending summary timers and writes their summary lines.
+func (rl *rateLimiter) flushAll(core *logger) {
+	rl.mu.Lock()
+	var keys []rateKey
+	for k, b := range rl.buckets {
+		if b.timer != nil {
+			b.timer.Stop()
+			keys = append(keys, k)
+		}
+	}
+	rl.mu.Unlock()
+	for _, k := range keys {
+		rl.flush(core, k)
+	}
+}
+
+// SetRateLimit limits the number of lines, of each group of lines that share
+// a key, that the standard logger writes to n per interval, with bursts of up
+// to burst lines.
+func SetRateLimit(key RateLimitKey, n int, interval time.Duration, burst int) {
+	std.SetRateLimit(key, n, interval, burst)
+}
@@ -0,0 +1,144 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimitCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", Lshortfile).With("k", "v")
+	l.SetRateLimit(RateLimitCaller, 2, time.Hour, 3)
+	for i := 0; i < 10; i++ {
+		l.Errorf("attempt %d failed", i)
+	}
+	l.Info("other") // a different call site isn't limited
+	l.Close()
+	loc := "ratelimit_test.go:16"
+	expected := "" +
+		loc + ": ERROR: attempt 0 failed k=v\n" +
+		loc + ": ERROR: attempt 1 failed k=v\n" +
+		loc + ": ERROR: attempt 2 failed k=v\n" +
+		"ratelimit_test.go:18: INFO: other k=v\n" +
+		loc + ": ERROR: suppressed 7 similar messages (" + loc + ") k=v\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+func TestRateLimitTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0)
+	l.SetRateLimit(RateLimitTemplate, 1, time.Hour, 0)
+	l.Errorf("attempt %d failed", 0)
+	l.Errorf("attempt %d failed", 1)
+	l.Infof("attempt %d failed", 2)
+	l.Info("done")
+	l.Info("done")
+	l.Infofn(func() string { return "done" })
+	l.SetRateLimit(RateLimitTemplate, 0, 0, 0)
+	l.Info("done")
+	expected := "" +
+		"ERROR: attempt 0 failed\n" +
+		"INFO: done\n" +
+		"ERROR: suppressed 2 similar messages (\"attempt %d failed\")\n" +
+		"INFO: suppressed 2 similar messages (\"done\")\n" +
+		"INFO: done\n"
+	// the summaries are written in no particular order
+	lines := strings.SplitAfter(buf.String(), "\n")
+	if len(lines) != 6 || lines[0]+lines[1] != expected[:len(lines[0]+lines[1])] || lines[4] != "INFO: done\n" {
+		t.Fatalf("got %q; want %q", buf.String(), expected)
+	}
+	if !strings.Contains(expected, lines[2]) || !strings.Contains(expected, lines[3]) || lines[2] == lines[3] {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+func TestRateLimitRefill(t *testing.T) {
+	ts := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0)
+	l.SetRateLimit(RateLimitTemplate, 2, time.Second, 2)
+	l.rateLimiter().now = func() time.Time { return ts }
+	for i := 0; i < 3; i++ {
+		l.Info("a")
+	}
+	ts = ts.Add(500 * time.Millisecond) // one token
+	for i := 0; i < 3; i++ {
+		l.Info("a")
+	}
+	if n := strings.Count(buf.String(), "INFO: a\n"); n != 3 {
+		t.Errorf("got %d lines; want 3: %q", n, buf.String())
+	}
+	l.Close()
+	if !strings.HasSuffix(buf.String(), "INFO: suppressed 3 similar messages (\"a\")\n") {
+		t.Errorf("got %q; want a summary of 3 suppressed messages", buf.String())
+	}
+}
+
+func TestRateLimitSweep(t *testing.T) {
+	ts := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0)
+	l.SetRateLimit(RateLimitTemplate, 1, time.Second, 1)
+	rl := l.rateLimiter()
+	rl.now = func() time.Time { return ts }
+	for i := 0; i < 5000; i++ {
+		l.Error("req ", i, " failed")
+	}
+	l.Error("req ", 0, " failed") // suppressed: its bucket isn't idle
+	ts = ts.Add(2 * time.Second)
+	for i := 5000; i < 10000; i++ {
+		l.Error("req ", i, " failed")
+	}
+	rl.mu.Lock()
+	n := len(rl.buckets)
+	_, pending := rl.buckets[rateKey{s: "req 0 failed"}]
+	rl.mu.Unlock()
+	if n > 5001 {
+		t.Errorf("got %d buckets; want the idle buckets removed", n)
+	}
+	if !pending {
+		t.Error("got the bucket with a pending summary removed; want it kept")
+	}
+	l.Close()
+	if !strings.HasSuffix(buf.String(), "ERROR: suppressed 1 similar messages (\"req 0 failed\")\n") {
+		t.Errorf("got %q; want the summary of the suppressed line", buf.String()[buf.Len()-100:])
+	}
+}
+
+func TestRateLimitWindow(t *testing.T) {
+	var w syncBuffer
+	l := New(LogDebug, Full, &w, "", 0)
+	l.SetRateLimit(RateLimitTemplate, 1, 10*time.Millisecond, 1)
+	l.Error("a")
+	l.Error("a")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(w.String(), "suppressed") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if w.String() != "ERROR: a\nERROR: suppressed 1 similar messages (\"a\")\n" {
+		t.Errorf("got %q; want the summary once the window closed", w.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
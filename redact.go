@@ -0,0 +1,174 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the secrets that are masked by redaction.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are the keys of the fields whose values are masked by
+// RedactDefaults.
+var DefaultRedactKeys = []string{"password", "token", "authorization"}
+
+// DefaultRedactPatterns are the patterns masked in messages by RedactDefaults:
+// bearer tokens and credit card numbers.
+var DefaultRedactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+	regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+}
+
+// A Redactor masks the secrets in a log line's message and fields. It must
+// not modify the fields slice it's given, which is shared with the Logger;
+// fields that are changed are returned in a new slice. The fields are returned
+// in the same order, without any being added or removed.
+type Redactor func(msg string, fields []Field) (string, []Field)
+
+// AddRedactor adds a Redactor that is applied to every line the logger writes,
+// after the redactors that were added before it. The lines are redacted before
+// they are written to any output, including RecordWriters.
+func (l *Logger) AddRedactor(r Redactor) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.redactors = append(l.redactors, r)
+}
+
+// RedactDefaults returns a Redactor that masks the values of the fields with
+// the DefaultRedactKeys and the DefaultRedactPatterns in messages.
+func RedactDefaults() Redactor {
+	keys := RedactKeys(DefaultRedactKeys...)
+	patterns := RedactPatterns(DefaultRedactPatterns...)
+	return func(msg string, fields []Field) (string, []Field) {
+		return patterns(keys(msg, fields))
+	}
+}
+
+// RedactKeys returns a Redactor that masks the values of the fields with any
+// of keys. Keys are matched without regard to case.
+func RedactKeys(keys ...string) Redactor {
+	return func(msg string, fields []Field) (string, []Field) {
+		copied := false
+		for i, f := range fields {
+			for _, k := range keys {
+				if !strings.EqualFold(f.Key, k) {
+					continue
+				}
+				if !copied {
+					fields = append([]Field(nil), fields...)
+					copied = true
+				}
+				fields[i].Value = Redacted
+				break
+			}
+		}
+		return msg, fields
+	}
+}
+
+// RedactPatterns returns a Redactor that masks the text matching any of the
+// patterns in messages, in the values of string fields, and in the messages of
+// the ErrorChains of lines written by Err. If a pattern has a parenthesized
+// subexpression, only the text matching the first one is masked, e.g. the
+// token, but not the "Bearer", of `(?i)bearer\s+(\S+)`.
+func RedactPatterns(patterns ...*regexp.Regexp) Redactor {
+	return func(msg string, fields []Field) (string, []Field) {
+		msg = redactPatterns(msg, patterns)
+		copied := false
+		for i, f := range fields {
+			var v interface{}
+			switch x := f.Value.(type) {
+			case string:
+				if r := redactPatterns(x, patterns); r != x {
+					v = r
+				}
+			case ErrorChain:
+				var chain ErrorChain
+				for j, e := range x {
+					if r := redactPatterns(e.Message, patterns); r != e.Message {
+						if chain == nil {
+							chain = append(ErrorChain(nil), x...)
+						}
+						chain[j].Message = r
+					}
+				}
+				if chain != nil {
+					v = chain
+				}
+			}
+			if v == nil {
+				continue
+			}
+			if !copied {
+				fields = append([]Field(nil), fields...)
+				copied = true
+			}
+			fields[i].Value = v
+		}
+		return msg, fields
+	}
+}
+
+// redactPatterns returns s with the text matching the patterns masked.
+func redactPatterns(s string, patterns []*regexp.Regexp) string {
+	for _, re := range patterns {
+		matches := re.FindAllStringSubmatchIndex(s, -1)
+		if matches == nil {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(Redacted)
+			last = end
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
+
+// redact returns a Logger with l's fields, and the msg, with the logger's
+// redactors applied. The caller must hold outMu.
+func (l *Logger) redact(msg []byte) (*Logger, []byte) {
+	s, fields := string(msg), l.fields
+	for _, r := range l.redactors {
+		s, fields = r(s, fields)
+	}
+	return &Logger{logger: l.logger, fields: fields, callDepth: l.callDepth, errFields: l.errFields}, []byte(s)
+}
+
+// redactMessage returns msg with the logger's redactors applied, e.g. for the
+// value passed to panic, which is written to stderr if it isn't recovered.
+func (l *Logger) redactMessage(msg string) string {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	for _, r := range l.redactors {
+		msg, _ = r(msg, l.fields)
+	}
+	return msg
+}
+
+// AddRedactor adds a Redactor that is applied to every line the standard
+// logger writes.
+func AddRedactor(r Redactor) {
+	std.AddRedactor(r)
+}
@@ -0,0 +1,119 @@
+package ezlog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const (
+	secretToken = "eyJhbGciOiJIUzI1NiJ9.c2VjcmV0.sig"
+	secretCard  = "4111 1111 1111 1111"
+)
+
+func TestRedactMethods(t *testing.T) {
+	msg := "Authorization: Bearer " + secretToken + " card " + secretCard
+	tests := []struct {
+		name string
+		f    func(l *Logger)
+	}{
+		{"Error", func(l *Logger) { l.Error(msg) }},
+		{"Errorf", func(l *Logger) { l.Errorf("%s", msg) }},
+		{"Errorln", func(l *Logger) { l.Errorln(msg) }},
+		{"Errorfn", func(l *Logger) { l.Errorfn(func() string { return msg }) }},
+		{"Info", func(l *Logger) { l.Info(msg) }},
+		{"Infof", func(l *Logger) { l.Infof("%s", msg) }},
+		{"Infoln", func(l *Logger) { l.Infoln(msg) }},
+		{"Infofn", func(l *Logger) { l.Infofn(func() string { return msg }) }},
+		{"Debug", func(l *Logger) { l.Debug(msg) }},
+		{"Debugf", func(l *Logger) { l.Debugf("%s", msg) }},
+		{"Debugln", func(l *Logger) { l.Debugln(msg) }},
+		{"Debugfn", func(l *Logger) { l.Debugfn(func() string { return msg }) }},
+		{"Print", func(l *Logger) { l.Print(msg) }},
+		{"Printf", func(l *Logger) { l.Printf("%s", msg) }},
+		{"Println", func(l *Logger) { l.Println(msg) }},
+		{"Panic", func(l *Logger) { defer recoverRedacted(t, "Panic"); l.Panic(msg) }},
+		{"Panicf", func(l *Logger) { defer recoverRedacted(t, "Panicf"); l.Panicf("%s", msg) }},
+		{"Panicln", func(l *Logger) { defer recoverRedacted(t, "Panicln"); l.Panicln(msg) }},
+	}
+	for _, format := range []Format{TextFormat, ConsoleFormat} {
+		for _, test := range tests {
+			var buf bytes.Buffer
+			var rw recordBuffer
+			l := New(LogDebug, Full, &buf, "", 0)
+			l.SetFormat(format)
+			l.AddOutput(&rw, LogDebug)
+			l.AddRedactor(RedactDefaults())
+			test.f(l.With("password", "hunter2", "Token", "t0k3n", "user", "arthur", "note", "Bearer "+secretToken))
+			line := buf.String()
+			for _, secret := range []string{secretToken, secretCard, "hunter2", "t0k3n"} {
+				if strings.Contains(line, secret) {
+					t.Errorf("%s %s: got %q in %q", format, test.name, secret, line)
+				}
+			}
+			if !strings.Contains(line, "Bearer "+Redacted+" card "+Redacted) || !strings.Contains(line, "user=arthur") {
+				t.Errorf("%s %s: got %q; want the secrets redacted", format, test.name, line)
+			}
+			if len(rw.records) != 1 {
+				t.Fatalf("%s %s: got %d records; want 1", format, test.name, len(rw.records))
+			}
+			r := rw.records[0]
+			if strings.Contains(r.Message, secretToken) || r.Fields[0].Value != Redacted || r.Fields[1].Value != Redacted || r.Fields[3].Value != "Bearer "+Redacted {
+				t.Errorf("%s %s: got record %q %v; want the secrets redacted", format, test.name, r.Message, r.Fields)
+			}
+		}
+	}
+}
+
+func TestRedactDoesNotModifyFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0).With("password", "hunter2", "note", "Bearer abc")
+	l.AddRedactor(RedactDefaults())
+	l.Info("a")
+	if l.Fields()[0].Value != "hunter2" || l.Fields()[1].Value != "Bearer abc" {
+		t.Errorf("got %v; want the logger's fields unchanged", l.Fields())
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	tests := []struct {
+		msg      string
+		expected string
+	}{
+		{"no secrets here", "no secrets here"},
+		{"authorization: bearer abc.def=", "authorization: bearer [REDACTED]"},
+		{"cards 4111-1111-1111-1111 and 378282246310005", "cards [REDACTED] and [REDACTED]"},
+		{"order 12345 of 2017", "order 12345 of 2017"},
+	}
+	r := RedactPatterns(DefaultRedactPatterns...)
+	for _, test := range tests {
+		if msg, _ := r(test.msg, nil); msg != test.expected {
+			t.Errorf("%q: got %q; want %q", test.msg, msg, test.expected)
+		}
+	}
+}
+
+func TestAddRedactor(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogInfo, Full, &buf, "", 0).With("ssn", "078-05-1120")
+	l.AddRedactor(RedactKeys("SSN"))
+	l.AddRedactor(RedactPatterns(regexp.MustCompile(`api_key=(\w+)`)))
+	l.AddRedactor(func(msg string, fields []Field) (string, []Field) {
+		return strings.Replace(msg, "arthur", "a****r", -1), fields
+	})
+	l.Info("arthur used api_key=abc123")
+	expected := "INFO: a****r used api_key=[REDACTED] ssn=[REDACTED]\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+// recoverRedacted recovers a panic and checks that its value was redacted.
+func recoverRedacted(t *testing.T, name string) {
+	v := recover()
+	s, ok := v.(string)
+	if !ok || strings.Contains(s, secretToken) || strings.Contains(s, secretCard) || !strings.Contains(s, "Bearer "+Redacted) {
+		t.Errorf("%s: got panic value %q; want the secrets redacted", name, v)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher applies the Config in a file to a Logger whenever the file changes
+// or, on unix systems, the process receives a SIGHUP. A Config that cannot be
+// loaded or applied is rejected; the Logger's current configuration is left as
+// is and the error is written to the Logger as an Error line.
+type Watcher struct {
+	l        *Logger
+	name     string
+	mu       sync.Mutex // protects the following fields
+	cfg      *Config    // the config that was last applied
+	modTime  time.Time
+	size     int64
+	sig      chan os.Signal
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// Watch applies the Config in the named file to l and then watches the file
+// for changes. The file is checked every interval and reloaded when either
+// its modification time or its size has changed; an interval <= 0 disables
+// checking the file. On unix systems, the file is also reloaded on SIGHUP. An
+// error is returned if the initial Config cannot be loaded or applied.
+func Watch(l *Logger, name string, interval time.Duration) (*Watcher, error) {
+	w := &Watcher{l: l, name: name, sig: make(chan os.Signal, 1), done: make(chan struct{})}
+	err := w.Reload()
+	if err != nil {
+		return nil, err
+	}
+	notifyReload(w.sig)
+	w.wg.Add(1)
+	go w.watch(interval)
+	return w, nil
+}
+
+// Reload loads the Config in the watched file and applies it to the Logger.
+// Level, level string type, prefix, flags, and format are always applied. The
+// outputs are only reopened if they differ from the ones that were last
+// applied; the files of the replaced outputs are closed. A line describing
+// what changed is written to the Logger using Print. If the Config cannot be
+// loaded or applied, the Logger is left unchanged and the error is returned.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fi, err := os.Stat(w.name)
+	if err != nil {
+		return err
+	}
+	c, err := LoadConfigFile(w.name)
+	if err != nil {
+		return err
+	}
+	s, err := c.parse()
+	if err != nil {
+		return err
+	}
+	var outs *configOutputs
+	if w.cfg == nil || !reflect.DeepEqual(w.cfg.Outputs, c.Outputs) {
+		outputs, closers, err := c.openOutputs(s.levels)
+		if err != nil {
+			return err
+		}
+		outs = &configOutputs{outputs: outputs, closers: closers}
+	}
+	changes := w.l.reconfigure(s, c.Prefix, outs)
+	w.cfg = c
+	w.modTime = fi.ModTime()
+	w.size = fi.Size()
+	if len(changes) > 0 {
+		w.l.Printf("ezlog: %s reloaded: %s", w.name, strings.Join(changes, ", "))
+	}
+	return nil
+}
+
+// Stop stops watching the file and stops relaying SIGHUP. It does not close
+// the Logger's outputs.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		signal.Stop(w.sig)
+		close(w.done)
+		w.wg.Wait()
+	})
+}
+
+func (w *Watcher) watch(interval time.Duration) {
+	defer w.wg.Done()
+	var tick <-chan time.Time
+	if interval > 0 {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.sig:
+		case <-tick:
+			if !w.changed() {
+				continue
+			}
+		}
+		w.reload()
+	}
+}
+
+// reload reloads the file and writes any error to the Logger.
+func (w *Watcher) reload() {
+	err := w.Reload()
+	if err != nil {
+		w.l.Errorf("ezlog: %s not reloaded; keeping the current configuration: %s", w.name, err)
+	}
+}
+
+// changed reports whether the file's modification time or size is different
+// than when it was last loaded.
+func (w *Watcher) changed() bool {
+	fi, err := os.Stat(w.name)
+	if err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !fi.ModTime().Equal(w.modTime) || fi.Size() != w.size
+}
+
+// configOutputs are the opened outputs of a Config.
+type configOutputs struct {
+	outputs []output
+	closers []io.Closer
+}
+
+// settings returns l's current settings and prefix. The level is the
+// configured level, which differs from the current level while it's elevated;
+// see SetLevelFor. The levels of the outputs are not included.
+func (l *Logger) settings() (s configSettings, prefix string) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	s.level = l.configuredLevel()
+	s.stringType = LevelStringType(atomic.LoadInt32(&l.stringType))
+	s.flag = l.flag
+	s.format = l.format
+	return s, l.prefix
+}
+
+// reconfigure atomically applies the settings, prefix and, if outs isn't nil,
+// the outputs to l; the files of the replaced outputs are closed. A
+// description of each change is returned.
+func (l *Logger) reconfigure(s configSettings, prefix string, outs *configOutputs) (changes []string) {
+	l.outMu.Lock()
+	if lvl := l.configuredLevel(); lvl != s.level {
+		changes = append(changes, fmt.Sprintf("level %s -> %s", lvl, s.level))
+		l.SetLevel(s.level)
+	}
+	if typ := LevelStringType(atomic.LoadInt32(&l.stringType)); typ != s.stringType {
+		changes = append(changes, fmt.Sprintf("level string type %s -> %s", typ, s.stringType))
+		atomic.StoreInt32(&l.stringType, int32(s.stringType))
+	}
+	if l.prefix != prefix {
+		changes = append(changes, fmt.Sprintf("prefix %q -> %q", l.prefix, prefix))
+		l.prefix = prefix
+	}
+	if l.flag != s.flag {
+		changes = append(changes, fmt.Sprintf("flags %s -> %s", strings.Join(FlagNames(l.flag), "|"), strings.Join(FlagNames(s.flag), "|")))
+		l.flag = s.flag
+	}
+	if l.format != s.format {
+		changes = append(changes, fmt.Sprintf("format %s -> %s", l.format, s.format))
+		l.format = s.format
+	}
+	var closers []io.Closer
+	if outs != nil {
+		changes = append(changes, "outputs replaced")
+		l.out = nil
+		if len(outs.outputs) == 0 {
+			l.out = os.Stderr
+		}
+		l.outputs = outs.outputs
+		closers = l.closers
+		l.closers = outs.closers
+	}
+	l.outMu.Unlock()
+	for _, c := range closers {
+		c.Close()
+	}
+	return changes
+}
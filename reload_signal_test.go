@@ -0,0 +1,40 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package ezlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWatcherSIGHUP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "log.json")
+	err = ioutil.WriteFile(name, []byte(`{"level": "info"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(LogError, Full, ioutil.Discard, "", 0)
+	// the file isn't checked, so only SIGHUP reloads it
+	w, err := Watch(l, name, 0)
+	if err != nil {
+		t.Fatalf("watch: unexpected error: %s", err)
+	}
+	defer w.Stop()
+	err = ioutil.WriteFile(name, []byte(`{"level": "debug", "flags": ["none"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	if !waitForLevel(l, LogDebug) {
+		t.Fatalf("level: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+}
@@ -0,0 +1,191 @@
+package ezlog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcherReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "log.json")
+	logName := filepath.Join(dir, "out.log")
+	cfg := `{"level": "error", "flags": ["none"], "outputs": [{"type": "file", "path": "` + logName + `"}]}`
+	err = ioutil.WriteFile(name, []byte(cfg), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(LogInfo, Full, ioutil.Discard, "", 0)
+	w, err := Watch(l, name, 0)
+	if err != nil {
+		t.Fatalf("watch: unexpected error: %s", err)
+	}
+	defer w.Stop()
+	if l.GetLevel() != LogError {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogError)
+	}
+	if l.Flags() != 0 {
+		t.Errorf("flags: got %d; want 0", l.Flags())
+	}
+	l.Info("info")
+	l.Error("error")
+
+	cfg = `{"level": "debug", "prefix": "x ", "flags": ["none"], "outputs": [{"type": "file", "path": "` + logName + `"}]}`
+	err = ioutil.WriteFile(name, []byte(cfg), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Reload()
+	if err != nil {
+		t.Fatalf("reload: unexpected error: %s", err)
+	}
+	if l.GetLevel() != LogDebug {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	if l.Prefix() != "x " {
+		t.Errorf("prefix: got %q; want \"x \"", l.Prefix())
+	}
+	l.Debug("debug")
+
+	// an invalid config must not change anything
+	err = ioutil.WriteFile(name, []byte(`{"level": "verbose"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Reload()
+	if err == nil {
+		t.Error("reload invalid config: expected an error; got none")
+	}
+	if l.GetLevel() != LogDebug {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	l.Info("info")
+	l.Close()
+
+	b, err := ioutil.ReadFile(logName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "ezlog: " + name + " reloaded: level INFO -> ERROR, outputs replaced\n" +
+		"ERROR: error\n" +
+		"x ezlog: " + name + " reloaded: level ERROR -> DEBUG, prefix \"\" -> \"x \"\n" +
+		"x DEBUG: debug\n" +
+		"x INFO: info\n"
+	if string(b) != expected {
+		t.Errorf("got %q; want %q", b, expected)
+	}
+}
+
+func TestWatcherWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "log.json")
+	err = ioutil.WriteFile(name, []byte(`{"level": "none"}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(LogError, Full, ioutil.Discard, "", 0)
+	w, err := Watch(l, name, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("watch: unexpected error: %s", err)
+	}
+	defer w.Stop()
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	err = ioutil.WriteFile(name, []byte(`{"level": "info", "flags": ["none"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !waitForLevel(l, LogInfo) {
+		t.Fatalf("level: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+
+	// an invalid config is reported on the logger
+	err = ioutil.WriteFile(name, []byte(`{"level": "debug", "flags": ["zdate"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Stop()
+	w.reload()
+	if !strings.Contains(buf.String(), "ERROR: ezlog: "+name+" not reloaded; keeping the current configuration: ") {
+		t.Errorf("got %q; want the reload error to be logged", buf.String())
+	}
+	if l.GetLevel() != LogInfo {
+		t.Errorf("level: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+}
+
+func waitForLevel(l *Logger, lvl Level) bool {
+	for i := 0; i < 200; i++ {
+		if l.GetLevel() == lvl {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestWatcherReloadElevated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "log.json")
+	err = ioutil.WriteFile(name, []byte(`{"level": "error", "flags": ["none"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(LogError, Full, ioutil.Discard, "", 0)
+	w, err := Watch(l, name, 0)
+	if err != nil {
+		t.Fatalf("watch: unexpected error: %s", err)
+	}
+	defer w.Stop()
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	cancel := l.SetLevelFor(LogDebug, time.Hour)
+	defer cancel()
+
+	// the configured level hasn't changed: nothing to report or change
+	err = w.Reload()
+	if err != nil {
+		t.Fatalf("reload: unexpected error: %s", err)
+	}
+	if strings.Contains(buf.String(), "level") || l.GetLevel() != LogDebug {
+		t.Errorf("got %q, level %s; want no level change", buf.String(), l.GetLevel())
+	}
+
+	err = ioutil.WriteFile(name, []byte(`{"level": "info", "flags": ["none"]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Reload()
+	if err != nil {
+		t.Fatalf("reload: unexpected error: %s", err)
+	}
+	if !strings.Contains(buf.String(), "level ERROR -> INFO") {
+		t.Errorf("got %q; want the configured level's change", buf.String())
+	}
+	if l.GetLevel() != LogDebug {
+		t.Errorf("elevated: got %s; want %s", l.GetLevel(), LogDebug)
+	}
+	if s := NewHandler(l).current(); s.Level != LogInfo.String() {
+		t.Errorf("handler: got %s; want %s", s.Level, LogInfo)
+	}
+	cancel()
+	if l.GetLevel() != LogInfo {
+		t.Errorf("after elevation: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// RotatingFile is an io.WriteCloser that writes to a file and rotates it once
+// its size would exceed a maximum. On rotation, the current file is renamed to
+// name.1, the existing name.1 is renamed to name.2, and so on; backups beyond
+// the maximum number of backups are removed. This is safe for concurrent use.
+type RotatingFile struct {
+	mu         sync.Mutex
+	name       string
+	maxSize    int64
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens the named file for appending, creating it if
+// necessary. The file is rotated when a write would make it larger than
+// maxSize bytes; a maxSize <= 0 disables rotation. At most maxBackups rotated
+// files are kept.
+func OpenRotatingFile(name string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{name: name, maxSize: maxSize, maxBackups: maxBackups}
+	err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write writes p to the file, rotating the file first if the write would make
+// it larger than the maximum size. A write that is larger than the maximum
+// size on its own is written to a freshly rotated file.
+func (r *RotatingFile) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		err = r.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err = r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// Name returns the name of the file being written to.
+func (r *RotatingFile) Name() string {
+	return r.name
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = fi.Size()
+	return nil
+}
+
+// rotate closes the current file, shifts the backups, and opens a new file.
+// The caller must hold the lock.
+func (r *RotatingFile) rotate() error {
+	err := r.f.Close()
+	r.f = nil
+	if err != nil {
+		return err
+	}
+	if r.maxBackups <= 0 {
+		err = os.Remove(r.name)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return r.open()
+	}
+	os.Remove(r.backupName(r.maxBackups))
+	for i := r.maxBackups - 1; i > 0; i-- {
+		err = os.Rename(r.backupName(i), r.backupName(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	err = os.Rename(r.name, r.backupName(1))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return r.open()
+}
+
+func (r *RotatingFile) backupName(i int) string {
+	return r.name + "." + strconv.Itoa(i)
+}
@@ -0,0 +1,84 @@
+package ezlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "test.log")
+	r, err := OpenRotatingFile(name, 10, 2)
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	for _, s := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"} {
+		_, err = r.Write([]byte(s))
+		if err != nil {
+			t.Fatalf("write %q: %s", s, err)
+		}
+	}
+	err = r.Close()
+	if err != nil {
+		t.Fatalf("close: %s", err)
+	}
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name, "gggg\n"},
+		{name + ".1", "eeee\nffff\n"},
+		{name + ".2", "cccc\ndddd\n"},
+	}
+	for _, test := range tests {
+		b, err := ioutil.ReadFile(test.name)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%s: got %q; want %q", test.name, b, test.expected)
+		}
+	}
+	_, err = os.Stat(name + ".3")
+	if !os.IsNotExist(err) {
+		t.Errorf("%s.3: expected the file to not exist; got %v", name, err)
+	}
+	_, err = r.Write([]byte("closed"))
+	if err == nil {
+		t.Error("write after close: expected an error; got none")
+	}
+}
+
+func TestRotatingFileNoBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "test.log")
+	r, err := OpenRotatingFile(name, 5, 0)
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	r.Write([]byte("aaaa\n"))
+	r.Write([]byte("bbbb\n"))
+	r.Close()
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "bbbb\n" {
+		t.Errorf("got %q; want \"bbbb\\n\"", b)
+	}
+	_, err = os.Stat(name + ".1")
+	if !os.IsNotExist(err) {
+		t.Errorf("%s.1: expected the file to not exist; got %v", name, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Sampler decides which of a level's log lines are kept.
+type Sampler interface {
+	// Sample reports whether a line is kept. It's called for every line of
+	// the level, before the line is formatted, and may be called concurrently.
+	Sample() bool
+}
+
+// samplers is the Sampler of each level; nil means every line is kept.
+type samplers [LogDebug + 1]Sampler
+
+// SetSampler sets the Sampler that decides which of the Info or Debug lines
+// the logger keeps; lines that aren't kept are discarded before they are
+// formatted. Error lines are always kept: only LogInfo and LogDebug may have a
+// Sampler. A nil s keeps every line of lvl.
+func (l *Logger) SetSampler(lvl Level, s Sampler) {
+	if lvl != LogInfo && lvl != LogDebug {
+		return
+	}
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	var ss samplers
+	if old, ok := l.samplers.Load().(*samplers); ok {
+		ss = *old
+	}
+	ss[lvl] = s
+	l.samplers.Store(&ss)
+}
+
+// sampledOut reports whether a line of level lvl isn't kept by lvl's Sampler.
+func (l *Logger) sampledOut(lvl Level) bool {
+	ss, ok := l.samplers.Load().(*samplers)
+	if !ok || lvl < 0 || int(lvl) >= len(ss) || ss[lvl] == nil {
+		return false
+	}
+	return !ss[lvl].Sample()
+}
+
+type ratioSampler float64
+
+// NewRatioSampler returns a Sampler that keeps each line with a probability
+// of ratio, e.g. 0.1 keeps about 1 in 10 lines.
+func NewRatioSampler(ratio float64) Sampler {
+	return ratioSampler(ratio)
+}
+
+func (r ratioSampler) Sample() bool {
+	if r >= 1 {
+		return true
+	}
+	return r > 0 && rand.Float64() < float64(r)
+}
+
+type firstEverySampler struct {
+	first int
+	every int
+	now   func() time.Time
+	mu    sync.Mutex
+	start time.Time // of the current second
+	n     int       // lines in the current second
+}
+
+// NewFirstEverySampler returns a Sampler that keeps the first lines of each
+// second, then every every-th line of that second. An every of 0 or less
+// discards the rest of the second's lines.
+func NewFirstEverySampler(first, every int) Sampler {
+	return &firstEverySampler{first: first, every: every, now: time.Now}
+}
+
+func (s *firstEverySampler) Sample() bool {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= time.Second || now.Before(s.start) {
+		s.start = now
+		s.n = 0
+	}
+	s.n++
+	if s.n <= s.first {
+		return true
+	}
+	return s.every > 0 && (s.n-s.first)%s.every == 0
+}
+
+// SetSampler sets the Sampler that decides which of the Info or Debug lines
+// the standard logger keeps.
+func SetSampler(lvl Level, s Sampler) {
+	std.SetSampler(lvl, s)
+}
@@ -0,0 +1,124 @@
+package ezlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countSampler keeps every other line and counts the lines it was asked about.
+type countSampler struct {
+	n int
+}
+
+func (s *countSampler) Sample() bool {
+	s.n++
+	return s.n%2 == 1
+}
+
+func TestSetSampler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(LogDebug, Full, &buf, "", 0)
+	var info, debug, errs countSampler
+	l.SetSampler(LogInfo, &info)
+	l.SetSampler(LogDebug, &debug)
+	l.SetSampler(LogError, &errs) // errors are always kept
+	for i := 0; i < 2; i++ {
+		l.Error("e")
+		l.Info("i")
+		l.Infof("%s", "f")
+		l.Infoln("l")
+		l.Infofn(func() string { return "fn" })
+		l.Debug("d")
+		l.Print("p")
+	}
+	expected := "ERROR: e\nINFO: i\nINFO: l\nDEBUG: d\np\nERROR: e\nINFO: i\nINFO: l\np\n"
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+	if info.n != 8 || debug.n != 2 || errs.n != 0 {
+		t.Errorf("got %d, %d, %d samples; want 8, 2, 0", info.n, debug.n, errs.n)
+	}
+	buf.Reset()
+	l.SetSampler(LogInfo, nil)
+	l.Info("a")
+	l.Info("b")
+	if buf.String() != "INFO: a\nINFO: b\n" {
+		t.Errorf("got %q; want %q", buf.String(), "INFO: a\nINFO: b\n")
+	}
+}
+
+func TestSamplerBeforeFormat(t *testing.T) {
+	l := New(LogDebug, Full, &bytes.Buffer{}, "", 0)
+	l.SetSampler(LogDebug, NewRatioSampler(0))
+	var formatted bool
+	l.Debugf("%v", stringerFunc(func() string { formatted = true; return "" }))
+	l.Debugfn(func() string { formatted = true; return "" })
+	if formatted {
+		t.Error("got a dropped line formatted; want it discarded before formatting")
+	}
+}
+
+type stringerFunc func() string
+
+func (f stringerFunc) String() string { return f() }
+
+func TestRatioSampler(t *testing.T) {
+	tests := []struct {
+		ratio    float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{-1, 0, 0},
+		{1, 1000, 1000},
+		{2, 1000, 1000},
+		{0.5, 350, 650},
+	}
+	for _, test := range tests {
+		s := NewRatioSampler(test.ratio)
+		var n int
+		for i := 0; i < 1000; i++ {
+			if s.Sample() {
+				n++
+			}
+		}
+		if n < test.min || n > test.max {
+			t.Errorf("%v: got %d of 1000 kept; want %d-%d", test.ratio, n, test.min, test.max)
+		}
+	}
+}
+
+func TestFirstEverySampler(t *testing.T) {
+	ts := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	tests := []struct {
+		first, every int
+		expected     string
+	}{
+		{2, 3, "xx..x..x..|xx..x"},
+		{0, 1, "xxxxxxxxxx|xxxxx"},
+		{1, 0, "x.........|x...."},
+		{0, 0, "..........|....."},
+	}
+	for _, test := range tests {
+		s := NewFirstEverySampler(test.first, test.every).(*firstEverySampler)
+		now := ts
+		s.now = func() time.Time { return now }
+		var b strings.Builder
+		for i := 0; i < 15; i++ {
+			if i == 10 {
+				now = now.Add(time.Second)
+				b.WriteByte('|')
+			}
+			if s.Sample() {
+				b.WriteByte('x')
+			} else {
+				b.WriteByte('.')
+			}
+			now = now.Add(time.Millisecond)
+		}
+		if b.String() != test.expected {
+			t.Errorf("%d, %d: got %s; want %s", test.first, test.every, b.String(), test.expected)
+		}
+	}
+}
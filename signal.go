@@ -0,0 +1,99 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package ezlog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// SignalCycler steps a Logger's level up or down on SIGUSR1 and SIGUSR2.
+type SignalCycler struct {
+	l        *Logger
+	sig      chan os.Signal
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// CycleLevelOnSignals starts listening for SIGUSR1 and SIGUSR2. On SIGUSR1,
+// l's level is stepped up, increasing verbosity: LogError to LogInfo to
+// LogDebug. On SIGUSR2, it is stepped down: LogDebug to LogInfo to LogError.
+// A Logger whose level is LogNone is stepped up to LogError. While l's level
+// is elevated by SetLevelFor, the level that will be restored is stepped.
+// Each transition is written to l using Print. Call Stop to stop listening.
+func CycleLevelOnSignals(l *Logger) *SignalCycler {
+	c := &SignalCycler{l: l, sig: make(chan os.Signal, 1), done: make(chan struct{})}
+	signal.Notify(c.sig, syscall.SIGUSR1, syscall.SIGUSR2)
+	c.wg.Add(1)
+	go c.listen()
+	return c
+}
+
+// Stop stops listening for SIGUSR1 and SIGUSR2.
+func (c *SignalCycler) Stop() {
+	c.stopOnce.Do(func() {
+		signal.Stop(c.sig)
+		close(c.done)
+		c.wg.Wait()
+	})
+}
+
+func (c *SignalCycler) listen() {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-c.done:
+			return
+		case s := <-c.sig:
+			// step from the configured level: while the level is elevated,
+			// SetLevel only sets the level that is restored.
+			prev := c.l.configuredLevel()
+			lvl := stepLevel(prev, s == syscall.SIGUSR1)
+			if lvl == prev {
+				c.l.Printf("ezlog: %s: level unchanged: %s", s, lvl)
+				continue
+			}
+			c.l.SetLevel(lvl)
+			c.l.Printf("ezlog: %s: level %s -> %s", s, prev, lvl)
+		}
+	}
+}
+
+// stepLevel returns the level that is one step up, more verbose, or down from
+// lvl. Stepping is bound by LogError and LogDebug, except that LogNone can't
+// be stepped down.
+func stepLevel(lvl Level, up bool) Level {
+	if up {
+		if lvl < LogError {
+			return LogError
+		}
+		if lvl < LogDebug {
+			return lvl + 1
+		}
+		return LogDebug
+	}
+	if lvl > LogDebug {
+		return LogDebug
+	}
+	if lvl > LogError {
+		return lvl - 1
+	}
+	return lvl
+}
@@ -0,0 +1,30 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package ezlog
+
+// SignalCycler steps a Logger's level up or down on SIGUSR1 and SIGUSR2.
+// This system doesn't have those signals: the level is never stepped.
+type SignalCycler struct{}
+
+// CycleLevelOnSignals returns a SignalCycler that does nothing; this system
+// doesn't have SIGUSR1 and SIGUSR2.
+func CycleLevelOnSignals(l *Logger) *SignalCycler {
+	return &SignalCycler{}
+}
+
+// Stop does nothing.
+func (c *SignalCycler) Stop() {}
@@ -0,0 +1,81 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package ezlog
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestStepLevel(t *testing.T) {
+	tests := []struct {
+		lvl      Level
+		up       bool
+		expected Level
+	}{
+		{LogNone, true, LogError},
+		{LogError, true, LogInfo},
+		{LogInfo, true, LogDebug},
+		{LogDebug, true, LogDebug},
+		{LogDebug, false, LogInfo},
+		{LogInfo, false, LogError},
+		{LogError, false, LogError},
+		{LogNone, false, LogNone},
+	}
+	for _, test := range tests {
+		v := stepLevel(test.lvl, test.up)
+		if v != test.expected {
+			t.Errorf("%s up %v: got %s; want %s", test.lvl, test.up, v, test.expected)
+		}
+	}
+}
+
+func TestCycleLevelOnSignals(t *testing.T) {
+	var buf syncBuffer
+	l := New(LogError, Full, &buf, "", 0)
+	c := CycleLevelOnSignals(l)
+	defer c.Stop()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	if !waitForLevel(l, LogInfo) {
+		t.Fatalf("SIGUSR1: got %s; want %s", l.GetLevel(), LogInfo)
+	}
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	if !waitForLevel(l, LogError) {
+		t.Fatalf("SIGUSR2: got %s; want %s", l.GetLevel(), LogError)
+	}
+	c.Stop()
+	s := buf.String()
+	if !strings.Contains(s, "ezlog: user defined signal 1: level ERROR -> INFO\n") {
+		t.Errorf("got %q; want the SIGUSR1 transition to be logged", s)
+	}
+	if !strings.Contains(s, "ezlog: user defined signal 2: level INFO -> ERROR\n") {
+		t.Errorf("got %q; want the SIGUSR2 transition to be logged", s)
+	}
+}
+
+func TestCycleLevelOnSignalsElevated(t *testing.T) {
+	var buf syncBuffer
+	l := New(LogInfo, Full, &buf, "", 0)
+	cancel := l.SetLevelFor(LogDebug, time.Hour)
+	c := CycleLevelOnSignals(l)
+	defer c.Stop()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	for i := 0; i < 200 && !strings.Contains(buf.String(), "level"); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.Stop()
+	if s := buf.String(); !strings.Contains(s, "ezlog: user defined signal 2: level INFO -> ERROR\n") {
+		t.Errorf("got %q; want the configured level to be stepped", s)
+	}
+	if l.GetLevel() != LogDebug {
+		t.Errorf("got %s; want %s while elevated", l.GetLevel(), LogDebug)
+	}
+	cancel()
+	if l.GetLevel() != LogError {
+		t.Errorf("got %s; want %s after the elevation ends", l.GetLevel(), LogError)
+	}
+}
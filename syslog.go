@@ -0,0 +1,217 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogFormat is the framing used for syslog messages.
+type SyslogFormat int
+
+const (
+	RFC5424 SyslogFormat = iota // the syslog protocol: RFC 5424
+	RFC3164                     // BSD syslog: RFC 3164
+)
+
+// Facility is a syslog facility.
+type Facility int
+
+const (
+	FacilityKern     Facility = 0
+	FacilityUser     Facility = 1
+	FacilityMail     Facility = 2
+	FacilityDaemon   Facility = 3
+	FacilityAuth     Facility = 4
+	FacilitySyslog   Facility = 5
+	FacilityLPR      Facility = 6
+	FacilityNews     Facility = 7
+	FacilityUUCP     Facility = 8
+	FacilityCron     Facility = 9
+	FacilityAuthPriv Facility = 10
+	FacilityFTP      Facility = 11
+	FacilityLocal0   Facility = 16
+	FacilityLocal1   Facility = 17
+	FacilityLocal2   Facility = 18
+	FacilityLocal3   Facility = 19
+	FacilityLocal4   Facility = 20
+	FacilityLocal5   Facility = 21
+	FacilityLocal6   Facility = 22
+	FacilityLocal7   Facility = 23
+)
+
+// syslog severities
+const (
+	sevAlert  = 1
+	sevCrit   = 2
+	sevErr    = 3
+	sevNotice = 5
+	sevInfo   = 6
+	sevDebug  = 7
+)
+
+// syslogSeverity maps a Level to a syslog severity. Lines without a level are
+// notices.
+var syslogSeverity = []int{
+	0:        sevNotice,
+	LogNone:  sevNotice,
+	LogError: sevErr,
+	LogInfo:  sevInfo,
+	LogDebug: sevDebug,
+	logFatal: sevCrit,
+	logPanic: sevAlert,
+}
+
+// SyslogWriter is an output that sends each log line to a syslog daemon as a
+// message whose severity corresponds to the line's level:
+//
+//	LogError:  err
+//	LogInfo:   info
+//	LogDebug:  debug
+//	Fatal:     crit
+//	Panic:     alert
+//	Print:     notice
+//
+// Because the message has its own timestamp and severity, the Logger's
+// prefix, header, and level string are not included in it. If a write fails,
+// the connection is reestablished and the write is retried once. This is safe
+// for concurrent use.
+type SyslogWriter struct {
+	network  string
+	raddr    string
+	format   SyslogFormat
+	facility Facility
+	appName  string
+	hostname string
+	pid      int
+	mu       sync.Mutex // protects conn
+	conn     net.Conn
+}
+
+// DialSyslog establishes a connection to the syslog daemon at raddr on the
+// named network: "unixgram", "udp", or "tcp", including their variants, e.g.
+// "udp4". If network is empty, a connection is made to the local syslog
+// daemon's unix datagram socket and raddr is ignored. Messages are framed
+// according to format and sent with the facility and app name. If appName is
+// empty, the base name of os.Args[0] is used. Over tcp, messages are framed
+// using octet counting, RFC 6587.
+func DialSyslog(network, raddr string, format SyslogFormat, facility Facility, appName string) (*SyslogWriter, error) {
+	if facility < FacilityKern || facility > FacilityLocal7 {
+		return nil, errors.New("ezlog: invalid syslog facility: " + strconv.Itoa(int(facility)))
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+	w := &SyslogWriter{network: network, raddr: raddr, format: format, facility: facility, appName: appName, hostname: hostname, pid: os.Getpid()}
+	err := w.connect()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write sends p as a message without a level; its severity is notice.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	err := w.WriteRecord(&Record{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteRecord sends r as a message whose severity corresponds to r.Level.
+func (w *SyslogWriter) WriteRecord(r *Record) error {
+	msg := w.format.frame(w.facility, r, w.hostname, w.appName, w.pid)
+	if w.stream() {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		_, err := w.conn.Write([]byte(msg))
+		if err == nil {
+			return nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	err := w.connect()
+	if err != nil {
+		return err
+	}
+	_, err = w.conn.Write([]byte(msg))
+	return err
+}
+
+// Close closes the connection to the syslog daemon.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// connect connects to the syslog daemon. The caller must hold the lock,
+// unless w hasn't been returned by DialSyslog yet.
+func (w *SyslogWriter) connect() error {
+	if w.network != "" {
+		c, err := net.Dial(w.network, w.raddr)
+		if err != nil {
+			return err
+		}
+		w.conn = c
+		return nil
+	}
+	for _, addr := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+		c, err := net.Dial("unixgram", addr)
+		if err == nil {
+			w.conn = c
+			return nil
+		}
+	}
+	return errors.New("ezlog: unix syslog delivery error")
+}
+
+// stream reports whether messages are sent over a stream and need framing.
+func (w *SyslogWriter) stream() bool {
+	return strings.HasPrefix(w.network, "tcp")
+}
+
+// frame returns the message for r.
+func (f SyslogFormat) frame(facility Facility, r *Record, hostname, appName string, pid int) string {
+	sev := sevNotice
+	if int(r.Level) < len(syslogSeverity) {
+		sev = syslogSeverity[r.Level]
+	}
+	pri := "<" + strconv.Itoa(int(facility)<<3|sev) + ">"
+	if f == RFC3164 {
+		return pri + r.Time.Format(time.Stamp) + " " + hostname + " " + appName + "[" + strconv.Itoa(pid) + "]: " + r.Message
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+	return pri + "1 " + r.Time.Format("2006-01-02T15:04:05.000000Z07:00") + " " + hostname + " " + appName + " " + strconv.Itoa(pid) + " - - " + r.Message
+}
@@ -0,0 +1,160 @@
+package ezlog
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogFrame(t *testing.T) {
+	tm := time.Date(2017, time.March, 4, 5, 6, 7, 123456789, time.UTC)
+	tests := []struct {
+		format   SyslogFormat
+		facility Facility
+		level    Level
+		expected string
+	}{
+		{RFC5424, FacilityUser, LogError, "<11>1 2017-03-04T05:06:07.123456Z host app 42 - - msg"},
+		{RFC5424, FacilityLocal0, LogInfo, "<134>1 2017-03-04T05:06:07.123456Z host app 42 - - msg"},
+		{RFC5424, FacilityDaemon, LogDebug, "<31>1 2017-03-04T05:06:07.123456Z host app 42 - - msg"},
+		{RFC5424, FacilityUser, logFatal, "<10>1 2017-03-04T05:06:07.123456Z host app 42 - - msg"},
+		{RFC5424, FacilityUser, logPanic, "<9>1 2017-03-04T05:06:07.123456Z host app 42 - - msg"},
+		{RFC5424, FacilityUser, 0, "<13>1 2017-03-04T05:06:07.123456Z host app 42 - - msg"},
+		{RFC3164, FacilityUser, LogError, "<11>Mar  4 05:06:07 host app[42]: msg"},
+		{RFC3164, FacilityLocal7, LogDebug, "<191>Mar  4 05:06:07 host app[42]: msg"},
+	}
+	for _, test := range tests {
+		r := Record{Time: tm, Level: test.level, Message: "msg"}
+		v := test.format.frame(test.facility, &r, "host", "app", 42)
+		if v != test.expected {
+			t.Errorf("got %q; want %q", v, test.expected)
+		}
+	}
+}
+
+func TestDialSyslogInvalidFacility(t *testing.T) {
+	_, err := DialSyslog("udp", "127.0.0.1:514", RFC5424, 24, "app")
+	if err == nil {
+		t.Error("expected an error; got none")
+	}
+}
+
+func TestSyslogWriterUnixgram(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ezlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "log.sock")
+	c, err := net.ListenPacket("unixgram", addr)
+	if err != nil {
+		t.Skipf("unixgram not supported: %s", err)
+	}
+	defer c.Close()
+	w, err := DialSyslog("unixgram", addr, RFC3164, FacilityLocal1, "app")
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	l := New(LogDebug, Full, nil, "prefix", LstdFlags)
+	l.AddOutput(w, LogInfo)
+	l.Debug("not sent")
+	l.Info("info")
+	l.Errorln("error")
+	expected := []string{
+		"<142>",
+		"<139>",
+	}
+	pid := "app[" + strconv.Itoa(os.Getpid()) + "]: "
+	msgs := []string{"info", "error"}
+	buf := make([]byte, 1024)
+	for i, prefix := range expected {
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := c.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("read: %s", err)
+		}
+		s := string(buf[:n])
+		if !strings.HasPrefix(s, prefix) {
+			t.Errorf("got %q; want prefix %q", s, prefix)
+		}
+		if !strings.HasSuffix(s, " "+pid+msgs[i]) {
+			t.Errorf("got %q; want suffix %q", s, " "+pid+msgs[i])
+		}
+	}
+}
+
+func TestSyslogWriterUDP(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	w, err := DialSyslog("udp", c.LocalAddr().String(), RFC5424, FacilityUser, "app")
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	_, err = w.Write([]byte("hello\n"))
+	if err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	buf := make([]byte, 1024)
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := c.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	s := string(buf[:n])
+	if !strings.HasPrefix(s, "<13>1 ") || !strings.HasSuffix(s, " app "+strconv.Itoa(os.Getpid())+" - - hello") {
+		t.Errorf("got %q; want a notice from app with message \"hello\"", s)
+	}
+}
+
+func TestSyslogWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	w, err := DialSyslog("tcp", ln.Addr().String(), RFC5424, FacilityUser, "app")
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	defer w.Close()
+	c, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	l := New(LogDebug, Full, w, "", 0)
+	l.Error("one")
+	l.Debug("two")
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(c)
+	for _, msg := range []string{"<11>1 ", "<15>1 "} {
+		n, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatalf("read length: %s", err)
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil {
+			t.Fatalf("length: %s", err)
+		}
+		b := make([]byte, size)
+		_, err = io.ReadFull(r, b)
+		if err != nil {
+			t.Fatalf("read: %s", err)
+		}
+		if !strings.HasPrefix(string(b), msg) {
+			t.Errorf("got %q; want prefix %q", b, msg)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2017 Joel Scoble
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the Free
+// Software Foundation, version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along with
+// this program. If not, see <http://www.gnu.org/licenses/>.
+
+package ezlog
+
+import "time"
+
+// TimeFormatUnixMilli is a time format for timestamps in milliseconds since
+// the Unix epoch.
+const TimeFormatUnixMilli = "unixmilli"
+
+// GetTimeFormat returns the logger's time format.
+func (l *Logger) GetTimeFormat() string {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.timeFormat
+}
+
+// SetTimeFormat sets the format of the timestamp at the beginning of each log
+// line. The format is either a layout accepted by time.Time.Format, e.g.
+// time.RFC3339Nano, or TimeFormatUnixMilli. When set, the format supersedes
+// the Ldate, Ltime, and Lmicroseconds flags: the timestamp is always written,
+// regardless of the flags. LUTC is still used unless the logger has a
+// location. An empty format restores the use of the flags.
+func (l *Logger) SetTimeFormat(format string) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.timeFormat = format
+}
+
+// GetLocation returns the logger's location; nil means that the local time
+// zone, or UTC if LUTC is set, is used.
+func (l *Logger) GetLocation() *time.Location {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.location
+}
+
+// SetLocation sets the time zone of the timestamps in log lines. The location
+// supersedes LUTC. A nil loc restores the use of the local time zone, or UTC if
+// LUTC is set.
+func (l *Logger) SetLocation(loc *time.Location) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.location = loc
+}
+
+// SetClock sets the func the logger uses to get the time of each log line,
+// e.g. a func returning a fixed time for deterministic tests. The time is also
+// the Time of the Records given to RecordWriters. A nil now restores the use
+// of time.Now.
+func (l *Logger) SetClock(now func() time.Time) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.clock = now
+}
+
+// now returns the current time from the logger's clock. The caller must hold
+// outMu.
+func (l *Logger) now() time.Time {
+	if l.clock == nil {
+		return time.Now()
+	}
+	return l.clock()
+}
+
+// GetTimeFormat returns the standard logger's time format.
+func GetTimeFormat() string {
+	return std.GetTimeFormat()
+}
+
+// SetTimeFormat sets the format of the timestamp at the beginning of each of
+// the standard logger's log lines.
+func SetTimeFormat(format string) {
+	std.SetTimeFormat(format)
+}
+
+// GetLocation returns the standard logger's location.
+func GetLocation() *time.Location {
+	return std.GetLocation()
+}
+
+// SetLocation sets the time zone of the timestamps in the standard logger's
+// log lines.
+func SetLocation(loc *time.Location) {
+	std.SetLocation(loc)
+}
+
+// SetClock sets the func the standard logger uses to get the time of each log
+// line.
+func SetClock(now func() time.Time) {
+	std.SetClock(now)
+}
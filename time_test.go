@@ -0,0 +1,69 @@
+package ezlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+	ts := time.Date(2009, 1, 23, 1, 23, 23, 123456789, time.UTC)
+	est := time.FixedZone("EST", -5*60*60)
+	tests := []struct {
+		format   string
+		location *time.Location
+		flag     int
+		expected string
+	}{
+		{"", nil, LstdFlags | LUTC, "2009/01/23 01:23:23 INFO: a\n"},
+		{"", est, LstdFlags | Lmicroseconds | LUTC, "2009/01/22 20:23:23.123456 INFO: a\n"},
+		{time.RFC3339Nano, nil, LUTC, "2009-01-23T01:23:23.123456789Z INFO: a\n"},
+		{time.RFC3339Nano, est, LstdFlags | LUTC, "2009-01-22T20:23:23.123456789-05:00 INFO: a\n"},
+		{TimeFormatUnixMilli, est, 0, "1232673803123 INFO: a\n"},
+		{"15:04:05.000", time.UTC, Lshortfile, "01:23:23.123 time_test.go:34: INFO: a\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		l := New(LogInfo, Full, &buf, "", test.flag)
+		l.SetClock(func() time.Time { return ts })
+		l.SetTimeFormat(test.format)
+		l.SetLocation(test.location)
+		if l.GetTimeFormat() != test.format || l.GetLocation() != test.location {
+			t.Errorf("%q: got %q, %v; want %q, %v", test.format, l.GetTimeFormat(), l.GetLocation(), test.format, test.location)
+		}
+		l.Info("a")
+		if buf.String() != test.expected {
+			t.Errorf("%q: got %q; want %q", test.format, buf.String(), test.expected)
+		}
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	ts := time.Date(2009, 1, 23, 1, 23, 23, 0, time.UTC)
+	var rw recordBuffer
+	l := New(LogInfo, Full, &rw, "", LstdFlags)
+	l.SetClock(func() time.Time { return ts })
+	l.Info("a")
+	if len(rw.records) != 1 || !rw.records[0].Time.Equal(ts) {
+		t.Fatalf("got %v; want a record at %s", rw.records, ts)
+	}
+	l.SetClock(nil)
+	l.Info("b")
+	if time.Since(rw.records[1].Time) > time.Minute {
+		t.Errorf("got %s; want the current time", rw.records[1].Time)
+	}
+}
+
+// recordBuffer is a RecordWriter that keeps the Records written to it.
+type recordBuffer struct {
+	records []Record
+}
+
+func (b *recordBuffer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (b *recordBuffer) WriteRecord(r *Record) error {
+	b.records = append(b.records, *r)
+	return nil
+}